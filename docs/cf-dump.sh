@@ -0,0 +1,144 @@
+#!/usr/bin/env bash
+#
+# Saves the Cloud Controller API responses needed to discover the applications of
+# a Cloud Foundry foundation offline. See docs/offline-discovery.md for the layout
+# of the dump.
+#
+# Usage: cf-dump.sh <output directory> [organization...]
+#
+# The script requires the cf CLI, logged in with `cf login`, and jq. Without
+# organizations, every organization visible to the user is dumped.
+#
+# Environment variables:
+#   CF_DUMP_REDACT=true   replaces the values of the credentials and password keys,
+#                         and of the environment variables of the applications,
+#                         with REDACTED.
+#   AUTOSCALER_URL=<url>  also dumps the App Autoscaler policies of the
+#                         applications, e.g. https://autoscale.sys.example.com.
+
+set -euo pipefail
+
+if [[ $# -lt 1 ]]; then
+  echo "usage: $0 <output directory> [organization...]" >&2
+  exit 1
+fi
+OUT=$1
+shift
+ORGS=("$@")
+
+command -v cf >/dev/null || { echo "the cf CLI is required" >&2; exit 1; }
+command -v jq >/dev/null || { echo "jq is required" >&2; exit 1; }
+
+# redact replaces the values of the credentials and password keys, and the values of
+# the environment_variables of the application environments, which commonly contain
+# secrets. The redacted environment variables keep their JSON type.
+redact() {
+  if [[ "${CF_DUMP_REDACT:-false}" == "true" ]]; then
+    jq 'walk(if type == "object" then with_entries(
+          if (.key | test("^(credentials|password)$"; "i")) then .value = "REDACTED"
+          elif .key == "environment_variables" and (.value | type) == "object" then
+            .value |= map_values(if type == "number" then 0 elif type == "boolean" then false elif type == "null" then null else "REDACTED" end)
+          else . end) else . end)'
+  else
+    cat
+  fi
+}
+
+# redact_manifest removes the values of the environment variables of the generated
+# manifests, which may contain credentials.
+redact_manifest() {
+  if [[ "${CF_DUMP_REDACT:-false}" == "true" ]]; then
+    sed -E '/^  env:/,/^  [a-z]/ s/^(    [^:]+:).*/\1 REDACTED/'
+  else
+    cat
+  fi
+}
+
+# save <endpoint> stores the response of a single resource in <endpoint>.json.
+save() {
+  local file="$OUT/${1%%\?*}.json"
+  mkdir -p "$(dirname "$file")"
+  cf curl "$1" | redact >"$file"
+}
+
+# save_list <endpoint> follows the pagination of a list endpoint and stores every
+# resource in a single page in <endpoint>.json.
+save_list() {
+  local file="$OUT/${1%%\?*}.json" next=$1 page resources='[]'
+  mkdir -p "$(dirname "$file")"
+  while [[ -n "$next" && "$next" != "null" ]]; do
+    page=$(cf curl "$next")
+    if jq -e '.errors' <<<"$page" >/dev/null; then
+      echo "failed to retrieve $next: $(jq -c '.errors' <<<"$page")" >&2
+      return 1
+    fi
+    resources=$(jq -c --argjson acc "$resources" '$acc + .resources' <<<"$page")
+    next=$(jq -r '.pagination.next.href // empty | sub("^https?://[^/]+"; "")' <<<"$page")
+  done
+  jq -n --argjson r "$resources" '{pagination: {total_results: ($r | length), total_pages: 1}, resources: $r}' | redact >"$file"
+}
+
+mkdir -p "$OUT"
+cf curl / >"$OUT/root.json"
+
+query=""
+if [[ ${#ORGS[@]} -gt 0 ]]; then
+  query="?names=$(IFS=,; echo "${ORGS[*]}")"
+fi
+save_list "/v3/organizations$query"
+org_guids=$(jq -r '[.resources[].guid] | join(",")' "$OUT/v3/organizations.json")
+if [[ -z "$org_guids" ]]; then
+  echo "no organization found" >&2
+  exit 1
+fi
+
+save_list "/v3/spaces?organization_guids=$org_guids&per_page=5000"
+save_list "/v3/apps?organization_guids=$org_guids&per_page=5000"
+save_list "/v3/routes?organization_guids=$org_guids&per_page=5000"
+save_list "/v3/service_route_bindings?per_page=5000"
+
+for app in $(jq -r '.resources[].guid' "$OUT/v3/apps.json"); do
+  echo "dumping app $app" >&2
+  save "/v3/apps/$app/env"
+  save_list "/v3/apps/$app/processes"
+  for process in $(jq -r '.resources[].guid' "$OUT/v3/apps/$app/processes.json"); do
+    # The command of the processes is only returned by the process endpoint
+    save "/v3/processes/$process"
+  done
+  save_list "/v3/apps/$app/routes"
+  save_list "/v3/apps/$app/sidecars"
+  save_list "/v3/apps/$app/features"
+  save "/v3/apps/$app/ssh_enabled"
+  if jq -e '.resources[] | select(.name == "revisions") | .enabled' "$OUT/v3/apps/$app/features.json" >/dev/null; then
+    save_list "/v3/apps/$app/revisions/deployed"
+  fi
+  # The stopped applications and the applications never staged have no droplet
+  if cf curl "/v3/apps/$app/droplets/current" | jq -e '.guid' >/dev/null 2>&1; then
+    save "/v3/apps/$app/droplets/current"
+    # The package the droplet was staged from, which may have been deleted since
+    package=$(jq -r '.links.package.href // empty | sub("^.*/"; "")' "$OUT/v3/apps/$app/droplets/current.json")
+    if [[ -n "$package" ]] && cf curl "/v3/packages/$package" | jq -e '.guid' >/dev/null 2>&1; then
+      save "/v3/packages/$package"
+    fi
+  fi
+  # The registry username of the docker applications is only kept in their packages
+  if jq -e --arg app "$app" '.resources[] | select(.guid == $app and .lifecycle.type == "docker")' "$OUT/v3/apps.json" >/dev/null; then
+    save_list "/v3/apps/$app/packages?types=docker&states=READY"
+  fi
+  # The manifest is a YAML document, while the errors are JSON documents
+  manifest=$(cf curl "/v3/apps/$app/manifest" 2>/dev/null || true)
+  if [[ -n "$manifest" && "$manifest" != "{"* ]]; then
+    redact_manifest <<<"$manifest" >"$OUT/v3/apps/$app/manifest.yml"
+  fi
+  if [[ -n "${AUTOSCALER_URL:-}" ]]; then
+    mkdir -p "$OUT/v1/apps/$app"
+    curl -sf -H "Authorization: $(cf oauth-token)" "${AUTOSCALER_URL%/}/v1/apps/$app/policy" >"$OUT/v1/apps/$app/policy.json" ||
+      rm -f "$OUT/v1/apps/$app/policy.json"
+  fi
+done
+
+for route in $(jq -r '.resources[].guid' "$OUT/v3/routes.json"); do
+  save "/v3/routes/$route/destinations"
+done
+
+echo "dump saved in $OUT" >&2
@@ -0,0 +1,28 @@
+// Command schemagen writes the JSON schema of the Cloud Foundry discovery manifest, generated from the Application type
+// and the comments of the Go files of the current directory. It is run by `go generate` in the cloud_foundry package:
+//
+//	go run github.com/konveyor/asset-generation/internal/cmd/schemagen -o schema.json
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"reflect"
+
+	"github.com/konveyor/asset-generation/internal/jsonschema"
+	cf "github.com/konveyor/asset-generation/pkg/providers/discoverers/cloud_foundry"
+)
+
+func main() {
+	out := flag.String("o", "schema.json", "path of the generated schema")
+	flag.Parse()
+
+	b, err := jsonschema.Generate(reflect.TypeOf(cf.Application{}), ".")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, append(b, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,268 @@
+// Package jsonschema generates the JSON Schema (draft 7) of a Go type from its reflection information, the comments of
+// the Go files that declare it and the constraints of its `validate` tags.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Draft7 is the URI of the JSON Schema draft 7 meta-schema.
+const Draft7 = "http://json-schema.org/draft-07/schema#"
+
+// Schema represents the subset of the JSON Schema draft 7 specification used to describe the discovery manifest.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	If                   *Schema            `json:"if,omitempty"`
+	Then                 *Schema            `json:"then,omitempty"`
+}
+
+// Generate returns the indented JSON schema of the type. The property names follow the encoding/json rules, the
+// descriptions are taken from the comments of the types and fields declared in the Go files of the directory, and the
+// constraints from the `validate` tags.
+func Generate(t reflect.Type, dir string) ([]byte, error) {
+	docs, err := parseTypeDocs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the documentation of the types: %w", err)
+	}
+	s := schemaForType(t, docs)
+	s.Schema = Draft7
+	s.Title = t.Name()
+	s.Description = docs[t.Name()]
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the JSON schema: %w", err)
+	}
+	return b, nil
+}
+
+// parseTypeDocs returns the comments of the types and struct fields declared in the Go files of the directory,
+// excluding the tests, keyed by the type name and by `<type name>.<field name>` respectively.
+func parseTypeDocs(dir string) (map[string]string, error) {
+	notTest := func(fi fs.FileInfo) bool { return !strings.HasSuffix(fi.Name(), "_test.go") }
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, notTest, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	docs := map[string]string{}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			addFileDocs(docs, f)
+		}
+	}
+	return docs, nil
+}
+
+// addFileDocs adds the comments of the types and struct fields declared in the file.
+func addFileDocs(docs map[string]string, f *ast.File) {
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			doc := ts.Doc
+			if doc == nil && len(gd.Specs) == 1 {
+				doc = gd.Doc
+			}
+			if doc != nil {
+				docs[ts.Name.Name] = commentText(doc)
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				if field.Doc == nil {
+					continue
+				}
+				names := field.Names
+				if len(names) == 0 {
+					// Embedded field, named after its type
+					if id, ok := field.Type.(*ast.Ident); ok {
+						names = []*ast.Ident{id}
+					}
+				}
+				for _, n := range names {
+					docs[ts.Name.Name+"."+n.Name] = commentText(field.Doc)
+				}
+			}
+		}
+	}
+}
+
+// commentText joins the lines of a comment group in a single line.
+func commentText(cg *ast.CommentGroup) string {
+	return strings.Join(strings.Fields(cg.Text()), " ")
+}
+
+// schemaForType returns the JSON schema for the given type following the encoding/json rules.
+func schemaForType(t reflect.Type, docs map[string]string) *Schema {
+	if t.Kind() == reflect.Pointer {
+		// Nil pointers are encoded as null
+		s := schemaForType(t.Elem(), docs)
+		if typ, ok := s.Type.(string); ok {
+			s.Type = []string{typ, "null"}
+		}
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		addStructProperties(s, t, docs)
+		return s
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem(), docs)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), docs)}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	// Interfaces accept any value
+	return &Schema{}
+}
+
+// addStructProperties adds the exported fields of the struct to the schema properties. Embedded structs without a
+// JSON name are flattened into the parent, as encoding/json does.
+func addStructProperties(s *Schema, t reflect.Type, docs map[string]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			addStructProperties(s, ft, docs)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		p := schemaForType(f.Type, docs)
+		p.Description = docs[t.Name()+"."+f.Name]
+		if applyValidateTag(p, f.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = p
+	}
+}
+
+// applyValidateTag translates the validator constraints into JSON schema keywords and reports whether the field is
+// required. The constraints after `dive` apply to the items of slices and to the values of maps.
+func applyValidateTag(s *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	required := false
+	target := s
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "omitempty":
+			optionalObject(target)
+		case "dive":
+			switch {
+			case target.Items != nil:
+				target = target.Items
+			case target.AdditionalProperties != nil:
+				target = target.AdditionalProperties
+			}
+		case "required":
+			required = required || target == s
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "min", "max":
+			v, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			setBound(target, name == "min", v)
+		}
+	}
+	return required
+}
+
+// optionalObject relaxes the required properties of an object so that they only apply when the object is not empty,
+// which matches the behavior of the validator with zero value structs that are tagged with `omitempty`. The objects
+// of pointer fields, whose type also accepts null, are relaxed the same way.
+func optionalObject(s *Schema) {
+	if baseType(s) != "object" || len(s.Required) == 0 {
+		return
+	}
+	one := 1
+	s.If = &Schema{MinProperties: &one}
+	s.Then = &Schema{Required: s.Required}
+	s.Required = nil
+}
+
+// setBound sets the minimum or maximum constraint that matches the type of the schema.
+func setBound(s *Schema, lower bool, v int) {
+	switch baseType(s) {
+	case "integer", "number":
+		if lower {
+			s.Minimum = &v
+		} else {
+			s.Maximum = &v
+		}
+	case "string":
+		if lower {
+			s.MinLength = &v
+		} else {
+			s.MaxLength = &v
+		}
+	case "array":
+		if lower {
+			s.MinItems = &v
+		} else {
+			s.MaxItems = &v
+		}
+	}
+}
+
+// baseType returns the type of the schema without null, e.g. `object` for the `[object, null]` type of a pointer.
+func baseType(s *Schema) string {
+	switch typ := s.Type.(type) {
+	case string:
+		return typ
+	case []string:
+		return typ[0]
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/konveyor/asset-generation/internal/jsonschema/test_data"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON schema generation", func() {
+	generate := func() Schema {
+		b, err := Generate(reflect.TypeOf(test_data.Deployment{}), "test_data")
+		Expect(err).NotTo(HaveOccurred())
+		var s Schema
+		Expect(json.Unmarshal(b, &s)).To(Succeed())
+		return s
+	}
+
+	It("describes the types declared in any file of the directory", func() {
+		s := generate()
+		Expect(s.Description).To(Equal("Deployment is a deployment of an application."))
+		Expect(s.Properties["probe"].Description).To(Equal("Probe is the optional probe of the deployment."))
+		Expect(s.Properties["probe"].Properties["path"].Description).To(Equal("Path is the HTTP path of the probe."))
+	})
+
+	It("only requires the properties of the optional pointer objects when they are set", func() {
+		probe := generate().Properties["probe"]
+		Expect(probe.Type).To(Equal([]any{"object", "null"}))
+		Expect(probe.Required).To(BeEmpty())
+		Expect(*probe.If.MinProperties).To(Equal(1))
+		Expect(probe.Then.Required).To(ConsistOf("path"))
+		Expect(*probe.Properties["path"].MinLength).To(Equal(1))
+	})
+})
@@ -0,0 +1,7 @@
+package test_data
+
+// Probe checks the health of the deployment.
+type Probe struct {
+	// Path is the HTTP path of the probe.
+	Path string `json:"path" validate:"required,min=1"`
+}
@@ -0,0 +1,9 @@
+package test_data
+
+// Deployment is a deployment of an application.
+type Deployment struct {
+	// Name is the name of the deployment.
+	Name string `json:"name" validate:"required"`
+	// Probe is the optional probe of the deployment.
+	Probe *Probe `json:"probe,omitempty" validate:"omitempty"`
+}
@@ -142,6 +142,8 @@ type AppManifestRoute struct {
 
 type AppRouteOptions struct {
 	LoadBalancing string `yaml:"loadbalancing"`
+	HashHeader    string `yaml:"hash_header,omitempty"`
+	HashBalance   string `yaml:"hash_balance,omitempty"`
 }
 
 type AppManifestSideCars []AppManifestSideCar
@@ -0,0 +1,74 @@
+package readiness
+
+import (
+	cf "github.com/konveyor/asset-generation/pkg/providers/discoverers/cloud_foundry"
+)
+
+const (
+	// maxScore is the score of an application without blockers nor warnings.
+	maxScore = 100
+	// blockerPenalty and warningPenalty are subtracted from the score for each blocker and warning.
+	blockerPenalty = 30
+	warningPenalty = 10
+)
+
+// effortRank orders the efforts from the lowest to the highest.
+var effortRank = map[Effort]int{LowEffort: 1, MediumEffort: 2, HighEffort: 3}
+
+// Assessor runs a set of rules over the discovered applications.
+type Assessor struct {
+	rules []Rule
+}
+
+// New returns an assessor that runs the given rules, or the default rules when none is given.
+func New(rules ...Rule) *Assessor {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	return &Assessor{rules: rules}
+}
+
+// Assess runs the rules over the application and returns its readiness report.
+func (a *Assessor) Assess(app cf.Application) Report {
+	r := Report{Application: app.Name, Space: app.Space, Score: maxScore, Readiness: Ready}
+	for _, rule := range a.rules {
+		for _, f := range rule.Evaluate(app) {
+			if f.Rule == "" {
+				f.Rule = rule.ID()
+			}
+			r.add(f)
+		}
+	}
+	return r
+}
+
+// AssessAll returns the readiness reports of the applications, in the same order.
+func (a *Assessor) AssessAll(apps []cf.Application) []Report {
+	reports := make([]Report, 0, len(apps))
+	for _, app := range apps {
+		reports = append(reports, a.Assess(app))
+	}
+	return reports
+}
+
+func (r *Report) add(f Finding) {
+	switch f.Severity {
+	case BlockerSeverity:
+		r.Blockers = append(r.Blockers, f)
+		r.Score -= blockerPenalty
+		r.Readiness = Blocked
+	case WarningSeverity:
+		r.Warnings = append(r.Warnings, f)
+		r.Score -= warningPenalty
+		if r.Readiness == Ready {
+			r.Readiness = NeedsChanges
+		}
+	default:
+		f.Severity = HintSeverity
+		r.Hints = append(r.Hints, f)
+	}
+	r.Score = max(r.Score, 0)
+	if effortRank[f.Effort] > effortRank[r.Effort] {
+		r.Effort = f.Effort
+	}
+}
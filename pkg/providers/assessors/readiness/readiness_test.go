@@ -0,0 +1,150 @@
+package readiness_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/konveyor/asset-generation/pkg/providers/assessors/readiness"
+	cf "github.com/konveyor/asset-generation/pkg/providers/discoverers/cloud_foundry"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Readiness assessment", func() {
+	rules := func(findings []readiness.Finding) []string {
+		ids := []string{}
+		for _, f := range findings {
+			ids = append(ids, f.Rule+" "+f.Path)
+		}
+		return ids
+	}
+
+	It("reports an application without findings as ready", func() {
+		r := readiness.New().Assess(cf.Application{
+			Metadata:   cf.Metadata{Name: "hello"},
+			Stack:      "cflinuxfs4",
+			Routes:     cf.RouteSpec{Routes: cf.Routes{{Route: "hello.example.com"}}},
+			Processes:  cf.Processes{{Type: cf.Web, ProcessSpecTemplate: cf.ProcessSpecTemplate{DiskQuota: "4G"}}},
+			BuildPacks: []string{"java_buildpack"},
+		})
+		Expect(r).To(Equal(readiness.Report{Application: "hello", Score: 100, Readiness: readiness.Ready}))
+	})
+
+	It("scores the blockers, warnings and hints of the default rules", func() {
+		r := readiness.New().Assess(cf.Application{
+			Metadata: cf.Metadata{Name: "legacy", Space: "prod"},
+			Stack:    "windows2016",
+			Services: cf.Services{{Name: "my-sso"}, {Name: "mysql"}, {Name: "config-server"}, {Name: "scaling", Label: "app-autoscaler"}},
+			Processes: cf.Processes{
+				{Type: cf.Web, ProcessSpecTemplate: cf.ProcessSpecTemplate{DiskQuota: "8GB"}},
+				{Type: cf.Worker, ProcessSpecTemplate: cf.ProcessSpecTemplate{DiskQuota: "4096M"}},
+			},
+			Routes: cf.RouteSpec{Routes: cf.Routes{
+				{Route: "tcp.example.com:1024", Protocol: cf.TCPRouteProtocol},
+				{Route: "legacy.example.com", RouteServiceURL: "https://waf.example.com"},
+			}},
+			Features:       map[string]bool{"ssh": true, "file-based-vcap-services": true},
+			ContainerImage: &cf.ContainerImage{UnmappedBuildpacks: []string{"custom_buildpack"}},
+		})
+		Expect(r.Readiness).To(Equal(readiness.Blocked))
+		Expect(r.Space).To(Equal("prod"))
+		Expect(r.Effort).To(Equal(readiness.HighEffort))
+		Expect(r.Score).To(Equal(0))
+		Expect(rules(r.Blockers)).To(Equal([]string{"windows-stack stack", "unsupported-service services[my-sso]"}))
+		Expect(rules(r.Warnings)).To(Equal([]string{
+			"autoscaler-service services[scaling]",
+			"large-disk-quota processes[web].disk",
+			"tcp-route routes[tcp.example.com:1024]",
+			"route-service routes[legacy.example.com]",
+			"ssh-enabled features.ssh",
+			"unmapped-buildpack buildPacks[custom_buildpack]",
+		}))
+		Expect(rules(r.Hints)).To(Equal([]string{
+			"service-binding-files features.file-based-vcap-services",
+			"platform-dependency services[config-server]",
+		}))
+	})
+
+	It("matches the unsupported services by offering or by name", func() {
+		r := readiness.New(readiness.UnsupportedServicesRule(readiness.DefaultUnsupportedServices...)).Assess(cf.Application{
+			Metadata: cf.Metadata{Name: "app"},
+			Services: cf.Services{{Name: "login", Label: "p-identity"}, {Name: "jobs-scheduler"}, {Name: "users", Label: "p.mysql"}},
+		})
+		Expect(rules(r.Blockers)).To(Equal([]string{"unsupported-service services[login]", "unsupported-service services[jobs-scheduler]"}))
+	})
+
+	It("reads the disk quotas without unit of the live discoveries as megabytes", func() {
+		r := readiness.New(readiness.DiskQuotaRule(readiness.DefaultMaxDiskQuotaMB)).Assess(cf.Application{
+			Metadata: cf.Metadata{Name: "app"},
+			Processes: cf.Processes{
+				{Type: cf.Web, ProcessSpecTemplate: cf.ProcessSpecTemplate{DiskQuota: "8192"}},
+				{Type: cf.Worker, ProcessSpecTemplate: cf.ProcessSpecTemplate{DiskQuota: "1024"}},
+			},
+		})
+		Expect(rules(r.Warnings)).To(Equal([]string{"large-disk-quota processes[web].disk"}))
+	})
+
+	It("does not report SSH when it is disabled for the space", func() {
+		r := readiness.New(readiness.SSHRule(), readiness.ServiceBindingFilesRule()).Assess(cf.Application{
+			Metadata: cf.Metadata{Name: "app"},
+			Features: map[string]bool{"ssh": true, "service-binding-k8s": true},
+			SSH:      &cf.SSHSettings{Enabled: false, Reason: "Disabled for space dev"},
+		})
+		Expect(r.Warnings).To(BeEmpty())
+		Expect(rules(r.Hints)).To(Equal([]string{"service-binding-files features.service-binding-k8s"}))
+	})
+
+	It("runs custom rules and lowers the score for each warning", func() {
+		noInstances := readiness.NewRule("single-instance", func(app cf.Application) []readiness.Finding {
+			var findings []readiness.Finding
+			for _, p := range app.Processes {
+				if p.Instances < 2 {
+					findings = append(findings, readiness.Finding{Severity: readiness.WarningSeverity, Message: "single instance", Effort: readiness.LowEffort})
+				}
+			}
+			return findings
+		})
+		r := readiness.New(noInstances, readiness.SSHRule()).Assess(cf.Application{
+			Metadata:  cf.Metadata{Name: "app"},
+			Processes: cf.Processes{{Type: cf.Web}, {Type: cf.Worker}},
+		})
+		Expect(r.Readiness).To(Equal(readiness.NeedsChanges))
+		Expect(r.Score).To(Equal(80))
+		Expect(r.Effort).To(Equal(readiness.LowEffort))
+		Expect(rules(r.Warnings)).To(Equal([]string{"single-instance ", "single-instance "}))
+	})
+
+	Context("emitting the reports", func() {
+		reports := readiness.New().AssessAll([]cf.Application{
+			{Metadata: cf.Metadata{Name: "ready"}},
+			{Metadata: cf.Metadata{Name: "a|b"}, Stack: "windows"},
+		})
+
+		It("writes the reports as JSON", func() {
+			var b bytes.Buffer
+			Expect(readiness.WriteJSON(&b, reports)).To(Succeed())
+			var decoded []readiness.Report
+			Expect(json.Unmarshal(b.Bytes(), &decoded)).To(Succeed())
+			Expect(decoded).To(Equal(reports))
+			Expect(b.String()).To(ContainSubstring(`"readiness": "blocked"`))
+		})
+
+		It("writes the reports as Markdown", func() {
+			var b bytes.Buffer
+			Expect(readiness.WriteMarkdown(&b, reports)).To(Succeed())
+			Expect(b.String()).To(Equal(`# Migration readiness
+
+| Application | Space | Score | Readiness | Blockers | Warnings | Hints | Effort |
+|---|---|---|---|---|---|---|---|
+| ready |  | 100 | ready | 0 | 0 | 0 |  |
+| a\|b |  | 70 | blocked | 1 | 0 | 0 | high |
+
+## a|b
+
+### Blockers
+
+- **windows-stack** ` + "`stack`" + `: the application runs on the windows stack: it requires Windows nodes in the cluster or porting to Linux (effort: high)
+`))
+		})
+	})
+})
@@ -0,0 +1,65 @@
+package readiness
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes the reports to w as an indented JSON array.
+func WriteJSON(w io.Writer, reports []Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if reports == nil {
+		reports = []Report{}
+	}
+	return enc.Encode(reports)
+}
+
+// WriteMarkdown writes the reports to w as a Markdown document: a summary table with a row per application, followed
+// by the findings of each application with blockers, warnings or hints.
+func WriteMarkdown(w io.Writer, reports []Report) error {
+	var b strings.Builder
+	b.WriteString("# Migration readiness\n\n")
+	b.WriteString("| Application | Space | Score | Readiness | Blockers | Warnings | Hints | Effort |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for _, r := range reports {
+		fmt.Fprintf(&b, "| %s | %s | %d | %s | %d | %d | %d | %s |\n", markdownCell(r.Application), markdownCell(r.Space),
+			r.Score, r.Readiness, len(r.Blockers), len(r.Warnings), len(r.Hints), r.Effort)
+	}
+	for _, r := range reports {
+		if len(r.Blockers)+len(r.Warnings)+len(r.Hints) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n", r.Application)
+		writeFindings(&b, "Blockers", r.Blockers)
+		writeFindings(&b, "Warnings", r.Warnings)
+		writeFindings(&b, "Hints", r.Hints)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeFindings(b *strings.Builder, title string, findings []Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+	for _, f := range findings {
+		fmt.Fprintf(b, "- **%s**", f.Rule)
+		if f.Path != "" {
+			fmt.Fprintf(b, " `%s`", f.Path)
+		}
+		fmt.Fprintf(b, ": %s", f.Message)
+		if f.Effort != "" {
+			fmt.Fprintf(b, " (effort: %s)", f.Effort)
+		}
+		b.WriteString("\n")
+	}
+}
+
+// markdownCell escapes the characters that break a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
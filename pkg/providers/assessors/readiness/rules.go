@@ -0,0 +1,307 @@
+package readiness
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	cf "github.com/konveyor/asset-generation/pkg/providers/discoverers/cloud_foundry"
+)
+
+// Rule evaluates a discovered application and returns its findings. Custom rules can be passed to New alongside, or
+// instead of, the default rules.
+type Rule interface {
+	// ID identifies the rule in the findings.
+	ID() string
+	// Evaluate returns the findings of the rule for the application, if any.
+	Evaluate(app cf.Application) []Finding
+}
+
+type ruleFunc struct {
+	id string
+	fn func(app cf.Application) []Finding
+}
+
+// NewRule returns a rule that evaluates the applications with the function.
+func NewRule(id string, fn func(app cf.Application) []Finding) Rule {
+	return ruleFunc{id: id, fn: fn}
+}
+
+func (r ruleFunc) ID() string {
+	return r.id
+}
+
+func (r ruleFunc) Evaluate(app cf.Application) []Finding {
+	return r.fn(app)
+}
+
+const (
+	// DefaultMaxDiskQuotaMB is the largest disk quota, in megabytes, that does not report a warning.
+	DefaultMaxDiskQuotaMB = 4096
+)
+
+// DefaultUnsupportedServices contains the patterns of the managed services that are specific to Cloud Foundry and have
+// no direct equivalent on Kubernetes: Single Sign-On, the Scheduler and the Metrics Forwarder. The App Autoscaler is
+// reported by AutoscalerRule instead, as its policy maps to a HorizontalPodAutoscaler.
+var DefaultUnsupportedServices = []string{"*sso*", "*identity*", "*scheduler*", "*metrics-forwarder*"}
+
+// autoscalerService matches the App Autoscaler services, e.g. `autoscaler` or `app-autoscaler`.
+var autoscalerService = regexp.MustCompile(`(?i)autoscal`)
+
+// DefaultRules returns the rules run by an assessor created without rules.
+func DefaultRules() []Rule {
+	return []Rule{
+		WindowsStackRule(),
+		UnsupportedServicesRule(DefaultUnsupportedServices...),
+		AutoscalerRule(),
+		DiskQuotaRule(DefaultMaxDiskQuotaMB),
+		TCPRoutesRule(),
+		RouteServicesRule(),
+		SSHRule(),
+		ServiceBindingFilesRule(),
+		UnmappedBuildpacksRule(),
+		PlatformDependenciesRule(),
+	}
+}
+
+// windowsStack matches the Windows stacks, e.g. `windows` or `windows2016`.
+var windowsStack = regexp.MustCompile(`(?i)^windows`)
+
+// WindowsStackRule reports a blocker for the applications that run on a Windows stack.
+func WindowsStackRule() Rule {
+	return NewRule("windows-stack", func(app cf.Application) []Finding {
+		if !windowsStack.MatchString(app.Stack) {
+			return nil
+		}
+		return []Finding{{
+			Severity: BlockerSeverity,
+			Path:     "stack",
+			Message:  fmt.Sprintf("the application runs on the %s stack: it requires Windows nodes in the cluster or porting to Linux", app.Stack),
+			Effort:   HighEffort,
+		}}
+	})
+}
+
+// UnsupportedServicesRule reports a blocker for each service of the application whose offering or name matches one of
+// the glob patterns, compared case insensitively. The offering is only known for the applications discovered from a
+// live foundation.
+func UnsupportedServicesRule(patterns ...string) Rule {
+	return NewRule("unsupported-service", func(app cf.Application) []Finding {
+		var findings []Finding
+		for _, svc := range app.Services {
+			for _, p := range patterns {
+				if matchService(p, svc) {
+					findings = append(findings, Finding{
+						Severity: BlockerSeverity,
+						Path:     fmt.Sprintf("services[%s]", svc.Name),
+						Message:  fmt.Sprintf("the service %s is a Cloud Foundry managed service without Kubernetes equivalent: replace it before the migration", svc.Name),
+						Effort:   HighEffort,
+					})
+					break
+				}
+			}
+		}
+		return findings
+	})
+}
+
+// AutoscalerRule reports a warning for each App Autoscaler service of the application, recognized by its offering or
+// its name: the scaling policy is mapped to a HorizontalPodAutoscaler, which must be reviewed.
+func AutoscalerRule() Rule {
+	return NewRule("autoscaler-service", func(app cf.Application) []Finding {
+		var findings []Finding
+		for _, svc := range app.Services {
+			if !autoscalerService.MatchString(svc.Label) && !autoscalerService.MatchString(svc.Name) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Severity: WarningSeverity,
+				Path:     fmt.Sprintf("services[%s]", svc.Name),
+				Message:  fmt.Sprintf("the service %s is the App Autoscaler: review the HorizontalPodAutoscaler generated from the `autoscaling` policy of the web process", svc.Name),
+				Effort:   LowEffort,
+			})
+		}
+		return findings
+	})
+}
+
+// matchService reports whether the offering or the name of the service matches the glob pattern, compared case
+// insensitively.
+func matchService(pattern string, svc cf.ServiceSpec) bool {
+	pattern = strings.ToLower(pattern)
+	for _, s := range []string{svc.Label, svc.Name} {
+		if ok, _ := path.Match(pattern, strings.ToLower(s)); ok && s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DiskQuotaRule reports a warning for each process whose disk quota exceeds the given size in megabytes.
+func DiskQuotaRule(maxMB int) Rule {
+	return NewRule("large-disk-quota", func(app cf.Application) []Finding {
+		var findings []Finding
+		for _, proc := range app.Processes {
+			mb, ok := megabytes(proc.DiskQuota)
+			if !ok || mb <= maxMB {
+				continue
+			}
+			findings = append(findings, Finding{
+				Severity: WarningSeverity,
+				Path:     fmt.Sprintf("processes[%s].disk", proc.Type),
+				Message:  fmt.Sprintf("the %s process requests %dM of disk, above %dM: request ephemeral storage accordingly or move the data to a persistent volume", proc.Type, mb, maxMB),
+				Effort:   MediumEffort,
+			})
+		}
+		return findings
+	})
+}
+
+// TCPRoutesRule reports a warning for each TCP route of the application.
+func TCPRoutesRule() Rule {
+	return NewRule("tcp-route", func(app cf.Application) []Finding {
+		var findings []Finding
+		for _, r := range app.Routes.Routes {
+			if r.Protocol != cf.TCPRouteProtocol {
+				continue
+			}
+			findings = append(findings, Finding{
+				Severity: WarningSeverity,
+				Path:     fmt.Sprintf("routes[%s]", r.Route),
+				Message:  fmt.Sprintf("the TCP route %s cannot be exposed with an Ingress: use a LoadBalancer or NodePort Service", r.Route),
+				Effort:   MediumEffort,
+			})
+		}
+		return findings
+	})
+}
+
+// RouteServicesRule reports a warning for each route of the application bound to a route service.
+func RouteServicesRule() Rule {
+	return NewRule("route-service", func(app cf.Application) []Finding {
+		var findings []Finding
+		for _, r := range app.Routes.Routes {
+			if r.RouteServiceURL == "" {
+				continue
+			}
+			findings = append(findings, Finding{
+				Severity: WarningSeverity,
+				Path:     fmt.Sprintf("routes[%s]", r.Route),
+				Message:  fmt.Sprintf("the route %s is bound to the route service %s: proxy the requests with the Ingress controller, the Gateway or a sidecar", r.Route, r.RouteServiceURL),
+				Effort:   MediumEffort,
+			})
+		}
+		return findings
+	})
+}
+
+// SSHRule reports a warning when the application enables the `ssh` feature, unless SSH is disabled for its space or
+// the foundation.
+func SSHRule() Rule {
+	return NewRule("ssh-enabled", func(app cf.Application) []Finding {
+		if !app.Features[cf.SSHFeature] || (app.SSH != nil && !app.SSH.Enabled) {
+			return nil
+		}
+		return []Finding{{
+			Severity: WarningSeverity,
+			Path:     "features.ssh",
+			Message:  "the application enables SSH access: replace the `cf ssh` workflows with `kubectl exec` and `kubectl port-forward`",
+			Effort:   LowEffort,
+		}}
+	})
+}
+
+// ServiceBindingFilesRule reports a hint when the application reads the credentials of its services from files,
+// with the `file-based-vcap-services` or `service-binding-k8s` features, instead of the VCAP_SERVICES environment
+// variable.
+func ServiceBindingFilesRule() Rule {
+	return NewRule("service-binding-files", func(app cf.Application) []Finding {
+		var findings []Finding
+		if app.Features[cf.FileBasedVCAPServicesFeature] {
+			findings = append(findings, Finding{
+				Severity: HintSeverity,
+				Path:     "features." + cf.FileBasedVCAPServicesFeature,
+				Message:  "the application reads VCAP_SERVICES from the file set in VCAP_SERVICES_FILE_PATH: mount the credentials as a Secret volume and set the variable to its path",
+				Effort:   LowEffort,
+			})
+		}
+		if app.Features[cf.ServiceBindingK8sFeature] {
+			findings = append(findings, Finding{
+				Severity: HintSeverity,
+				Path:     "features." + cf.ServiceBindingK8sFeature,
+				Message:  "the application reads the service bindings from SERVICE_BINDING_ROOT: project the Secrets with the Service Binding specification for Kubernetes",
+				Effort:   LowEffort,
+			})
+		}
+		return findings
+	})
+}
+
+// UnmappedBuildpacksRule reports a warning for each buildpack that the image catalog could not map to a container
+// image. It requires the discovery to resolve the container images.
+func UnmappedBuildpacksRule() Rule {
+	return NewRule("unmapped-buildpack", func(app cf.Application) []Finding {
+		if app.ContainerImage == nil {
+			return nil
+		}
+		var findings []Finding
+		for _, bp := range app.ContainerImage.UnmappedBuildpacks {
+			findings = append(findings, Finding{
+				Severity: WarningSeverity,
+				Path:     fmt.Sprintf("buildPacks[%s]", bp),
+				Message:  fmt.Sprintf("no container image is known for the buildpack %s: build the image with a custom Dockerfile", bp),
+				Effort:   MediumEffort,
+			})
+		}
+		return findings
+	})
+}
+
+// PlatformDependenciesRule reports a hint for each platform dependency of the application, taken from its migration
+// hints or detected from its services and environment variables when the discovery did not detect them.
+func PlatformDependenciesRule() Rule {
+	return NewRule("platform-dependency", func(app cf.Application) []Finding {
+		hints := app.MigrationHints
+		if hints == nil {
+			hints = cf.DetectMigrationHints(app)
+		}
+		findings := make([]Finding, 0, len(hints))
+		for _, h := range hints {
+			findings = append(findings, Finding{
+				Severity: HintSeverity,
+				Path:     h.Source,
+				Message:  fmt.Sprintf("%s: replace it with %s, %s", h.Dependency, h.Replacement, h.Message),
+				Effort:   MediumEffort,
+			})
+		}
+		return findings
+	})
+}
+
+// quantity matches the sizes of the Cloud Foundry manifests, e.g. `512M`, `1G` or `2GB`, and the sizes without unit
+// of the live discoveries, e.g. `8192`, which are expressed in megabytes as the API expresses them.
+var quantity = regexp.MustCompile(`(?i)^\s*(\d+)\s*(?:([KMGT])B?)?\s*$`)
+
+// megabytes returns the size in megabytes, the unit of the sizes without unit. It returns false when the size is not
+// valid.
+func megabytes(size string) (int, bool) {
+	m := quantity.FindStringSubmatch(size)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	switch strings.ToUpper(m[2]) {
+	case "K":
+		return n / 1024, true
+	case "G":
+		return n * 1024, true
+	case "T":
+		return n * 1024 * 1024, true
+	}
+	return n, true
+}
@@ -0,0 +1,66 @@
+package readiness
+
+// Severity is the impact of a finding on the migration of the application.
+type Severity string
+
+const (
+	// BlockerSeverity identifies the findings that prevent the application from running on Kubernetes until they are
+	// addressed.
+	BlockerSeverity Severity = "blocker"
+	// WarningSeverity identifies the findings that require changes to the application or to its deployment.
+	WarningSeverity Severity = "warning"
+	// HintSeverity identifies the findings that describe the work required to migrate the application without
+	// affecting its readiness.
+	HintSeverity Severity = "hint"
+)
+
+// Effort is the estimated amount of work required to address a finding.
+type Effort string
+
+const (
+	LowEffort    Effort = "low"
+	MediumEffort Effort = "medium"
+	HighEffort   Effort = "high"
+)
+
+// Readiness summarizes the findings of an application.
+type Readiness string
+
+const (
+	// Ready means that the application has no blockers nor warnings.
+	Ready Readiness = "ready"
+	// NeedsChanges means that the application has warnings but no blockers.
+	NeedsChanges Readiness = "needs-changes"
+	// Blocked means that the application has at least one blocker.
+	Blocked Readiness = "blocked"
+)
+
+type Finding struct {
+	// Rule is the ID of the rule that reported the finding.
+	Rule string `json:"rule"`
+	// Severity captures the impact of the finding on the migration.
+	Severity Severity `json:"severity"`
+	// Path identifies the field of the application that caused the finding, e.g. `stack` or `services[sso]`.
+	Path string `json:"path,omitempty"`
+	// Message describes the finding and how to address it.
+	Message string `json:"message"`
+	// Effort captures the estimated amount of work required to address the finding.
+	Effort Effort `json:"effort,omitempty"`
+}
+
+type Report struct {
+	// Application is the name of the assessed application.
+	Application string `json:"application"`
+	// Space is the space of the application, when it was discovered from a live foundation.
+	Space string `json:"space,omitempty"`
+	// Score rates the readiness of the application from 0 to 100. Each blocker and warning lowers the score.
+	Score int `json:"score"`
+	// Readiness summarizes the findings of the application.
+	Readiness Readiness `json:"readiness"`
+	// Effort is the highest effort of the findings. Empty when there are no findings.
+	Effort Effort `json:"effort,omitempty"`
+	// Blockers, Warnings and Hints contain the findings of each severity, in the order of the rules.
+	Blockers []Finding `json:"blockers,omitempty"`
+	Warnings []Finding `json:"warnings,omitempty"`
+	Hints    []Finding `json:"hints,omitempty"`
+}
@@ -0,0 +1,197 @@
+package cloud_foundry
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// archiveSeparator separates the path of an archive from the path of an entry in it, e.g.
+// `app.jar!/BOOT-INF/classes/manifest.yml`.
+const archiveSeparator = "!/"
+
+// maxArchiveManifestSize limits the size of the manifests read from archives.
+const maxArchiveManifestSize = 10 << 20
+
+// zipArchiveExtensions and tarArchiveExtensions are the extensions of the archives that are searched for manifests.
+var (
+	zipArchiveExtensions = []string{".zip", ".jar", ".war", ".ear"}
+	tarArchiveExtensions = []string{".tar.gz", ".tgz"}
+)
+
+func isZipArchive(name string) bool {
+	return hasExtension(name, zipArchiveExtensions)
+}
+
+func isTarArchive(name string) bool {
+	return hasExtension(name, tarArchiveExtensions)
+}
+
+// isArchive checks if the file is an archive that can contain manifests, based on its extension.
+func isArchive(name string) bool {
+	return isZipArchive(name) || isTarArchive(name)
+}
+
+func hasExtension(name string, extensions []string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range extensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// isArchiveManifest checks if an archive entry is a manifest. Only the YAML files whose name starts with `manifest`
+// are considered, since archives usually contain other YAML files such as the Spring Boot `application.yml`.
+func isArchiveManifest(entry string) bool {
+	return strings.HasPrefix(strings.ToLower(path.Base(entry)), "manifest") && hasYAMLExtension(entry)
+}
+
+// archiveEntryPath returns the path of the entry in the archive, e.g. `app.jar!/manifest.yml`.
+func archiveEntryPath(archive, entry string) string {
+	return archive + archiveSeparator + entry
+}
+
+// splitArchivePath splits the path of an archive entry into the path of the archive and the path of the entry in it.
+// It returns false when the path does not reference an archive entry.
+func splitArchivePath(p string) (string, string, bool) {
+	archive, entry, ok := strings.Cut(p, archiveSeparator)
+	if !ok || !isArchive(archive) {
+		return "", "", false
+	}
+	return archive, entry, true
+}
+
+// archiveManifests returns the paths of the manifests contained in the archive.
+func archiveManifests(archive string) ([]string, error) {
+	var manifests []string
+	err := walkArchive(archive, func(entry string, _ io.Reader) (bool, error) {
+		if isArchiveManifest(entry) {
+			manifests = append(manifests, archiveEntryPath(archive, entry))
+		}
+		return false, nil
+	})
+	return manifests, err
+}
+
+// readArchiveEntry returns the content of the entry in the archive.
+func readArchiveEntry(archive, entry string) ([]byte, error) {
+	var data []byte
+	found := false
+	err := walkArchive(archive, func(name string, r io.Reader) (bool, error) {
+		if name != entry {
+			return false, nil
+		}
+		found = true
+		var err error
+		data, err = io.ReadAll(io.LimitReader(r, maxArchiveManifestSize+1))
+		if err == nil && len(data) > maxArchiveManifestSize {
+			err = fmt.Errorf("entry %s exceeds the maximum manifest size of %d bytes", entry, maxArchiveManifestSize)
+		}
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("entry %s not found in archive %s: %w", entry, archive, os.ErrNotExist)
+	}
+	return data, nil
+}
+
+// readManifestFile returns the content of a manifest, which is either a file or an entry of an archive.
+func readManifestFile(filePath string) ([]byte, error) {
+	if archive, entry, ok := splitArchivePath(filePath); ok {
+		return readArchiveEntry(archive, entry)
+	}
+	return os.ReadFile(filePath)
+}
+
+// walkArchive calls fn with the path and the content of each regular file in the archive, until fn returns true or an
+// error.
+func walkArchive(archive string, fn func(entry string, r io.Reader) (bool, error)) error {
+	if isZipArchive(archive) {
+		return walkZipArchive(archive, fn)
+	}
+	return walkTarArchive(archive, fn)
+}
+
+func walkZipArchive(archive string, fn func(entry string, r io.Reader) (bool, error)) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %s: %w", archive, err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() || !isValidEntry(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open entry %s in archive %s: %w", f.Name, archive, err)
+		}
+		done, err := fn(f.Name, rc)
+		rc.Close()
+		if err != nil || done {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTarArchive(archive string, fn func(entry string, r io.Reader) (bool, error)) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %s: %w", archive, err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %s: %w", archive, err)
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive %s: %w", archive, err)
+		}
+		name := strings.TrimPrefix(h.Name, "./")
+		if h.Typeflag != tar.TypeReg || !isValidEntry(name) {
+			continue
+		}
+		if done, err := fn(name, tr); err != nil || done {
+			return err
+		}
+	}
+}
+
+// isValidEntry checks that the entry path is relative and does not leave the archive.
+func isValidEntry(name string) bool {
+	return filepath.IsLocal(filepath.FromSlash(name))
+}
+
+// archiveApplicationPath returns the path of the application bits of a manifest contained in an archive: the archive
+// itself when the manifest does not define a path, otherwise the entry referenced by the path relative to the
+// manifest. Paths that leave the archive are returned unchanged.
+func archiveApplicationPath(archive, manifestEntry, appPath string) string {
+	if appPath == "" {
+		return archive
+	}
+	entry := path.Join(path.Dir(manifestEntry), filepath.ToSlash(appPath))
+	if path.IsAbs(appPath) || !isValidEntry(entry) {
+		return appPath
+	}
+	return archiveEntryPath(archive, entry)
+}
@@ -0,0 +1,146 @@
+package cloud_foundry
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manifests inside archives", func() {
+	var (
+		logger = logr.New(logr.Discard().GetSink())
+		dir    string
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writeZip := func(name string, entries map[string]string) string {
+		archive := filepath.Join(dir, name)
+		f, err := os.Create(archive)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		w := zip.NewWriter(f)
+		for entry, content := range entries {
+			ew, err := w.Create(entry)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ew.Write([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(w.Close()).To(Succeed())
+		return archive
+	}
+
+	writeTarGz := func(name string, entries map[string]string) string {
+		archive := filepath.Join(dir, name)
+		f, err := os.Create(archive)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		gw := gzip.NewWriter(f)
+		tw := tar.NewWriter(gw)
+		for entry, content := range entries {
+			Expect(tw.WriteHeader(&tar.Header{Name: entry, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})).To(Succeed())
+			_, err := tw.Write([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(tw.Close()).To(Succeed())
+		Expect(gw.Close()).To(Succeed())
+		return archive
+	}
+
+	discover := func(manifestPath, appName string) (*DiscoveryDocument, error) {
+		p, err := New(&Config{ManifestPath: manifestPath}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := p.Discover(AppReference{AppName: appName})
+		if err != nil {
+			return nil, err
+		}
+		return ConvertDocument(result.Content)
+	}
+
+	It("discovers the manifest of a jar and points the application path at the archive", func() {
+		archive := writeZip("app.jar", map[string]string{
+			"BOOT-INF/classes/application.yml": "spring:\n  application:\n    name: other\n",
+			"BOOT-INF/classes/manifest.yml":    "name: app\nmemory: 1G\n",
+		})
+		doc, err := discover(archive, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Spec.Name).To(Equal("app"))
+		Expect(doc.Spec.Path).To(Equal(archive))
+		Expect(doc.Metadata.ManifestPath).To(Equal(archive + "!/BOOT-INF/classes/manifest.yml"))
+		Expect(doc.Metadata.Archive).To(Equal(archive))
+		Expect(doc.Metadata.ArchiveEntry).To(Equal("BOOT-INF/classes/manifest.yml"))
+	})
+
+	It("inspects the source of an archive given by a relative path", func() {
+		var err error
+		dir, err = os.MkdirTemp(".", "archive")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+		relative := writeZip("app.jar", map[string]string{
+			"META-INF/MANIFEST.MF":          "Manifest-Version: 1.0\nSpring-Boot-Version: 3.2.0\nBuild-Jdk-Spec: 17\n",
+			"BOOT-INF/classes/manifest.yml": "name: app\n",
+		})
+		Expect(filepath.IsAbs(relative)).To(BeFalse())
+		p, err := New(&Config{ManifestPath: relative, InspectSource: true}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Warnings).To(BeEmpty())
+		doc, err := ConvertDocument(result.Content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Spec.Path).To(Equal(relative))
+		Expect(doc.Spec.Runtime).NotTo(BeNil())
+		Expect(doc.Spec.Runtime.Framework).To(Equal(springBootFramework))
+	})
+
+	It("resolves the path of the application relative to the manifest entry", func() {
+		archive := writeTarGz("bundle.tar.gz", map[string]string{
+			"./deploy/manifest.yml": "applications:\n  - name: app\n    path: ../target/app.war\n",
+			"./target/app.war":      "",
+		})
+		doc, err := discover(archive, "app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Spec.Path).To(Equal(archive + "!/target/app.war"))
+		Expect(doc.Metadata.ArchiveEntry).To(Equal("deploy/manifest.yml"))
+	})
+
+	It("searches the archives of a manifest directory", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "manifest.yml"), []byte("name: plain-app\n"), 0644)).To(Succeed())
+		writeZip("first.war", map[string]string{"WEB-INF/manifest.yml": "name: war-app\n"})
+		writeTarGz("second.tgz", map[string]string{"manifest-prod.yaml": "name: tar-app\n"})
+
+		p, err := New(&Config{ManifestPath: dir}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		apps, err := p.ListApps()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apps[defaultLocalOrg]).To(ConsistOf(
+			AppReference{OrgName: defaultLocalOrg, SpaceName: defaultLocalSpace, AppName: "plain-app"},
+			AppReference{OrgName: defaultLocalOrg, SpaceName: defaultLocalSpace, AppName: "war-app"},
+			AppReference{OrgName: defaultLocalOrg, SpaceName: defaultLocalSpace, AppName: "tar-app"},
+		))
+
+		doc, err := discover(dir, "tar-app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Metadata.ManifestPath).To(Equal(filepath.Join(dir, "second.tgz") + "!/manifest-prod.yaml"))
+	})
+
+	It("returns ErrAppNotFound when the archive does not contain the application", func() {
+		archive := writeZip("app.zip", map[string]string{"README.md": "no manifest"})
+		_, err := discover(archive, "app")
+		Expect(err).To(MatchError(ErrAppNotFound))
+	})
+
+	It("reports the archive entry in validation errors", func() {
+		archive := writeZip("app.jar", map[string]string{"manifest.yml": "name: app\ntimeout: 500\n"})
+		_, err := discover(archive, "")
+		Expect(err).To(MatchError(ContainSubstring(archive + "!/manifest.yml:2:10: validation failed for manifest key 'timeout'")))
+	})
+})
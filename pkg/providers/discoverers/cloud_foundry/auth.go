@@ -0,0 +1,111 @@
+package cloud_foundry
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/cloudfoundry/go-cfclient/v3/config"
+)
+
+const (
+	// PasswordEnv is the environment variable read when the password of the password grant is not set in the
+	// configuration.
+	PasswordEnv = "CF_PASSWORD"
+	// ClientSecretEnv is the environment variable read when the client secret of the client credentials grant is not
+	// set in the configuration.
+	ClientSecretEnv = "CF_CLIENT_SECRET"
+)
+
+// AuthConfig declares how live discovery authenticates against the Cloud Foundry API, as an alternative to building
+// the go-cfclient configuration in code. The grant is selected from the fields that are set:
+//
+//   - Username: password grant, with the password read from Password or the CF_PASSWORD environment variable.
+//   - ClientID and a client secret, read from ClientSecret or the CF_CLIENT_SECRET environment variable: client
+//     credentials grant.
+//   - None of them: the target and the tokens saved by `cf login` in `.cf/config.json` of CFHome, which defaults to
+//     the CF_HOME environment variable or the home directory of the user.
+//
+// The access tokens are refreshed when they expire, so long discovery runs outlive the lifetime of the tokens.
+type AuthConfig struct {
+	// APIURL is the URL of the Cloud Foundry API, e.g. `https://api.sys.example.com`. Required by the password and
+	// client credentials grants. The CF CLI configuration provides its own target.
+	APIURL string `json:"api_url,omitempty" yaml:"api_url,omitempty"`
+	// CFHome is the directory that contains the `.cf/config.json` file of the CF CLI.
+	CFHome string `json:"cf_home,omitempty" yaml:"cf_home,omitempty"`
+	// Username and Password are the credentials of the password grant. The password is not serialized, so that the
+	// configuration can be written without leaking it: the configuration files rely on the CF_PASSWORD environment
+	// variable instead.
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"-" yaml:"-"`
+	// Origin is the identity provider of the user of the password grant, e.g. `ldap`. Defaults to the UAA users.
+	Origin string `json:"origin,omitempty" yaml:"origin,omitempty"`
+	// ClientID and ClientSecret are the credentials of the client credentials grant. The client ID is also used by
+	// the password grant, where it defaults to the `cf` client. As the password, the client secret is not serialized
+	// and the configuration files rely on the CF_CLIENT_SECRET environment variable instead.
+	ClientID     string `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	ClientSecret string `json:"-" yaml:"-"`
+	// TokenURL is the URL of the UAA that issues the tokens. Defaults to the UAA advertised by the API root.
+	TokenURL string `json:"token_url,omitempty" yaml:"token_url,omitempty"`
+	// SkipTLSValidation disables the validation of the certificates of the API and the UAA.
+	SkipTLSValidation bool `json:"skip_tls_validation,omitempty" yaml:"skip_tls_validation,omitempty"`
+}
+
+// NewConfigFromAuth returns a go-cfclient configuration authenticated as declared by the AuthConfig. Additional
+// options are applied after the ones of the AuthConfig. The errors caused by rejected credentials or tokens are
+// wrapped with ErrAuthentication.
+func NewConfigFromAuth(auth AuthConfig, options ...config.Option) (*config.Config, error) {
+	var opts []config.Option
+	if auth.SkipTLSValidation {
+		opts = append(opts, config.SkipTLSValidation())
+	}
+	if auth.TokenURL != "" {
+		opts = append(opts, config.AuthTokenURL(auth.TokenURL, auth.TokenURL))
+	}
+	secret := cmp.Or(auth.ClientSecret, os.Getenv(ClientSecretEnv))
+	switch {
+	case auth.Username != "":
+		password := cmp.Or(auth.Password, os.Getenv(PasswordEnv))
+		if password == "" {
+			return nil, fmt.Errorf("the password of user %s is not set: set it in the configuration or in the %s environment variable", auth.Username, PasswordEnv)
+		}
+		opts = append(opts, config.UserPassword(auth.Username, password), config.ClientCredentials(auth.ClientID, secret))
+		if auth.Origin != "" {
+			opts = append(opts, config.Origin(auth.Origin))
+		}
+	case auth.ClientID != "":
+		if secret == "" {
+			return nil, fmt.Errorf("the secret of client %s is not set: set it in the configuration or in the %s environment variable", auth.ClientID, ClientSecretEnv)
+		}
+		opts = append(opts, config.ClientCredentials(auth.ClientID, secret))
+	default:
+		if auth.APIURL != "" {
+			return nil, errors.New("the API URL requires a username or a client ID: the CF CLI configuration provides its own target")
+		}
+		return newConfigFromCFHome(auth.CFHome, append(opts, options...)...)
+	}
+	if auth.APIURL == "" {
+		return nil, errors.New("the API URL is required by the password and client credentials grants")
+	}
+	cfg, err := config.New(auth.APIURL, append(opts, options...)...)
+	return cfg, wrapAPIError(err)
+}
+
+// newConfigFromCFHome returns a go-cfclient configuration with the target and the tokens of the CF CLI configuration
+// stored in the directory, or in the default CF home directory when empty.
+func newConfigFromCFHome(dir string, options ...config.Option) (*config.Config, error) {
+	var (
+		cfg *config.Config
+		err error
+	)
+	if dir == "" {
+		cfg, err = config.NewFromCFHome(options...)
+	} else {
+		cfg, err = config.NewFromCFHomeDir(dir, options...)
+	}
+	if err != nil {
+		return nil, wrapAPIError(fmt.Errorf("failed to load the CF CLI configuration: %w", err))
+	}
+	return cfg, nil
+}
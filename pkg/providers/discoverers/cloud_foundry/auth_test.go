@@ -0,0 +1,146 @@
+package cloud_foundry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	"github.com/go-logr/logr"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+// fakeUAA issues tokens that expire after a second, so that every request of a discovery refreshes them, and records
+// the grants it receives.
+type fakeUAA struct {
+	*httptest.Server
+	mu     sync.Mutex
+	grants []string
+	reject bool
+}
+
+func newFakeUAA() *fakeUAA {
+	u := &fakeUAA{}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.ParseForm()).To(Succeed())
+		u.mu.Lock()
+		u.grants = append(u.grants, r.PostForm.Get("grant_type"))
+		u.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if u.reject {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"unauthorized","error_description":"Bad credentials"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"token","refresh_token":"refresh","token_type":"bearer","expires_in":1}`))
+	}))
+	DeferCleanup(u.Close)
+	return u
+}
+
+func (u *fakeUAA) Grants() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string{}, u.grants...)
+}
+
+var _ = Describe("Authentication", func() {
+	var (
+		logger = logr.New(logr.Discard().GetSink())
+		uaa    *fakeUAA
+		m      mockApplication
+		apiURL string
+	)
+
+	BeforeEach(func() {
+		uaa = newFakeUAA()
+		m, apiURL = newMockApplication(cfTypes.AppManifest{Name: "app", Metadata: &cfTypes.AppMetadata{}}, GlobalT)
+	})
+
+	AfterEach(func() {
+		testutil.Teardown()
+	})
+
+	discover := func(auth AuthConfig) error {
+		p, err := New(&Config{Auth: &auth}, &logger, false)
+		if err != nil {
+			return err
+		}
+		_, err = p.Discover(AppReference{OrgName: m.organization().Name, SpaceName: m.space().Name, AppName: m.application().Name})
+		return err
+	}
+
+	It("logs in with the password grant and refreshes the expired tokens", func() {
+		Expect(discover(AuthConfig{APIURL: apiURL, Username: "admin", Password: "secret", TokenURL: uaa.URL})).To(Succeed())
+		Expect(uaa.Grants()[0]).To(Equal("password"))
+		Expect(uaa.Grants()[1:]).To(ContainElement("refresh_token"))
+	})
+
+	It("reads the password from the environment", func() {
+		GinkgoT().Setenv(PasswordEnv, "secret")
+		Expect(discover(AuthConfig{APIURL: apiURL, Username: "admin", TokenURL: uaa.URL})).To(Succeed())
+		Expect(uaa.Grants()[0]).To(Equal("password"))
+	})
+
+	It("authenticates with the client credentials grant", func() {
+		GinkgoT().Setenv(ClientSecretEnv, "secret")
+		Expect(discover(AuthConfig{APIURL: apiURL, ClientID: "discovery", TokenURL: uaa.URL})).To(Succeed())
+		Expect(len(uaa.Grants())).To(BeNumerically(">", 1))
+		Expect(uaa.Grants()).To(HaveEach("client_credentials"))
+	})
+
+	It("does not serialize the password and the client secret", func() {
+		cfg := Config{Auth: &AuthConfig{APIURL: apiURL, Username: "admin", Password: "s3cr3t", ClientID: "discovery", ClientSecret: "t0ps3cr3t"}}
+		j, err := json.Marshal(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		y, err := yaml.Marshal(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		for _, b := range [][]byte{j, y} {
+			Expect(string(b)).To(ContainSubstring("admin"))
+			Expect(string(b)).NotTo(ContainSubstring("s3cr3t"))
+		}
+	})
+
+	It("uses the target and the tokens saved by the CF CLI", func() {
+		b, err := os.ReadFile("test_data/.cf/config.json")
+		Expect(err).NotTo(HaveOccurred())
+		cli := map[string]any{}
+		Expect(json.Unmarshal(b, &cli)).To(Succeed())
+		cli["Target"], cli["AuthorizationEndpoint"], cli["UaaEndpoint"] = apiURL, uaa.URL, uaa.URL
+		home := GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(home, ".cf"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(home, ".cf", "config.json"), []byte(toJSON(cli)), 0o600)).To(Succeed())
+		GinkgoT().Setenv("CF_HOME", home)
+		GinkgoT().Setenv("CF_USERNAME", "")
+		GinkgoT().Setenv("CF_PASSWORD", "")
+
+		Expect(discover(AuthConfig{})).To(Succeed())
+		By("refreshing the expired access token of the CF CLI configuration")
+		Expect(uaa.Grants()).NotTo(BeEmpty())
+		Expect(uaa.Grants()).To(HaveEach("refresh_token"))
+	})
+
+	It("returns ErrAuthentication when the UAA rejects the credentials", func() {
+		uaa.reject = true
+		err := discover(AuthConfig{APIURL: apiURL, Username: "admin", Password: "wrong", TokenURL: uaa.URL})
+		Expect(err).To(MatchError(ErrAuthentication))
+	})
+
+	DescribeTable("rejects incomplete configurations", func(auth AuthConfig, msg string) {
+		GinkgoT().Setenv(PasswordEnv, "")
+		GinkgoT().Setenv(ClientSecretEnv, "")
+		Expect(discover(auth)).To(MatchError(ContainSubstring(msg)))
+	},
+		Entry("a user without password", AuthConfig{APIURL: "https://api.example.com", Username: "admin"}, "the password of user admin is not set"),
+		Entry("a client without secret", AuthConfig{APIURL: "https://api.example.com", ClientID: "discovery"}, "the secret of client discovery is not set"),
+		Entry("a grant without API URL", AuthConfig{Username: "admin", Password: "secret"}, "the API URL is required"),
+		Entry("an API URL without credentials", AuthConfig{APIURL: "https://api.example.com"}, "requires a username or a client ID"),
+		Entry("a missing CF CLI configuration", AuthConfig{CFHome: "test_data/missing"}, "failed to load the CF CLI configuration"),
+	)
+})
@@ -0,0 +1,200 @@
+package cloud_foundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
+)
+
+// autoscalerPolicyPath is the path of the App Autoscaler API endpoint that returns the scaling policy of an
+// application.
+const autoscalerPolicyPath = "/v1/apps/%s/policy"
+
+// autoscalerServiceLabel matches the service offerings of the App Autoscaler in VCAP_SERVICES, e.g. `autoscaler` or
+// `app-autoscaler`.
+var autoscalerServiceLabel = regexp.MustCompile(`(?i)autoscal`)
+
+// autoscalerPolicy is the scaling policy as returned by the App Autoscaler API.
+// Reference: https://github.com/cloudfoundry/app-autoscaler-release/blob/main/docs/policy.md
+type autoscalerPolicy struct {
+	InstanceMinCount int `json:"instance_min_count"`
+	InstanceMaxCount int `json:"instance_max_count"`
+	ScalingRules     []struct {
+		MetricType         string `json:"metric_type"`
+		Threshold          int64  `json:"threshold"`
+		Operator           string `json:"operator"`
+		Adjustment         string `json:"adjustment"`
+		BreachDurationSecs int    `json:"breach_duration_secs"`
+		CoolDownSecs       int    `json:"cool_down_secs"`
+	} `json:"scaling_rules"`
+	Schedules *struct {
+		Timezone          string `json:"timezone"`
+		RecurringSchedule []struct {
+			StartTime               string `json:"start_time"`
+			EndTime                 string `json:"end_time"`
+			StartDate               string `json:"start_date"`
+			EndDate                 string `json:"end_date"`
+			DaysOfWeek              []int  `json:"days_of_week"`
+			DaysOfMonth             []int  `json:"days_of_month"`
+			InstanceMinCount        int    `json:"instance_min_count"`
+			InstanceMaxCount        int    `json:"instance_max_count"`
+			InitialMinInstanceCount int    `json:"initial_min_instance_count"`
+		} `json:"recurring_schedule"`
+		SpecificDate []struct {
+			StartDateTime           string `json:"start_date_time"`
+			EndDateTime             string `json:"end_date_time"`
+			InstanceMinCount        int    `json:"instance_min_count"`
+			InstanceMaxCount        int    `json:"instance_max_count"`
+			InitialMinInstanceCount int    `json:"initial_min_instance_count"`
+		} `json:"specific_date"`
+	} `json:"schedules"`
+}
+
+func (p autoscalerPolicy) toAutoscalingPolicy() *AutoscalingPolicy {
+	policy := &AutoscalingPolicy{MinInstances: p.InstanceMinCount, MaxInstances: p.InstanceMaxCount}
+	for _, r := range p.ScalingRules {
+		policy.Rules = append(policy.Rules, ScalingRule{
+			MetricType:            r.MetricType,
+			Threshold:             r.Threshold,
+			Operator:              r.Operator,
+			Adjustment:            r.Adjustment,
+			BreachDurationSeconds: r.BreachDurationSecs,
+			CoolDownSeconds:       r.CoolDownSecs,
+		})
+	}
+	if p.Schedules == nil {
+		return policy
+	}
+	policy.Schedules = &ScalingSchedules{Timezone: p.Schedules.Timezone}
+	for _, s := range p.Schedules.RecurringSchedule {
+		policy.Schedules.Recurring = append(policy.Schedules.Recurring, RecurringSchedule{
+			StartTime:           s.StartTime,
+			EndTime:             s.EndTime,
+			StartDate:           s.StartDate,
+			EndDate:             s.EndDate,
+			DaysOfWeek:          s.DaysOfWeek,
+			DaysOfMonth:         s.DaysOfMonth,
+			MinInstances:        s.InstanceMinCount,
+			MaxInstances:        s.InstanceMaxCount,
+			InitialMinInstances: s.InitialMinInstanceCount,
+		})
+	}
+	for _, s := range p.Schedules.SpecificDate {
+		policy.Schedules.SpecificDates = append(policy.Schedules.SpecificDates, SpecificDateSchedule{
+			StartDateTime:       s.StartDateTime,
+			EndDateTime:         s.EndDateTime,
+			MinInstances:        s.InstanceMinCount,
+			MaxInstances:        s.InstanceMaxCount,
+			InitialMinInstances: s.InitialMinInstanceCount,
+		})
+	}
+	return policy
+}
+
+// isBoundToAutoscaler checks if the VCAP_SERVICES of the application contain an App Autoscaler service binding.
+func isBoundToAutoscaler(env map[string]json.RawMessage) bool {
+	vcap, ok := env[vcapServices]
+	if !ok {
+		return false
+	}
+	labels := map[string]json.RawMessage{}
+	if err := json.Unmarshal(vcap, &labels); err != nil {
+		return false
+	}
+	for label := range labels {
+		if autoscalerServiceLabel.MatchString(label) {
+			return true
+		}
+	}
+	return false
+}
+
+// autoscalerURL returns the URL of the App Autoscaler API: the configured one, or the URL derived from the Cloud
+// Foundry API endpoint.
+func (c *CloudFoundryProvider) autoscalerURL() (string, error) {
+	if c.cfg.AutoscalerURL != "" {
+		return strings.TrimSuffix(c.cfg.AutoscalerURL, "/"), nil
+	}
+	return deriveAutoscalerURL(c.cfConfig.ApiURL(""))
+}
+
+// deriveAutoscalerURL replaces the `api` host label of the Cloud Foundry API URL with `autoscale`, as in the default
+// App Autoscaler deployments.
+func deriveAutoscalerURL(apiURL string) (string, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the Cloud Foundry API URL: %w", err)
+	}
+	domain, ok := strings.CutPrefix(u.Host, "api.")
+	if !ok {
+		return "", fmt.Errorf("unable to derive the App Autoscaler API URL from %s: set the autoscaler URL in the configuration", u.Host)
+	}
+	u.Host = "autoscale." + domain
+	u.Path = ""
+	return u.String(), nil
+}
+
+// getAutoscalingPolicy retrieves the scaling policy of the application from the App Autoscaler API. It returns nil
+// when the application has no policy.
+func (c *CloudFoundryProvider) getAutoscalingPolicy(appGUID string) (*AutoscalingPolicy, error) {
+	baseURL, err := c.autoscalerURL()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, baseURL+fmt.Sprintf(autoscalerPolicyPath, appGUID), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.cli.ExecuteAuthRequest(req)
+	var httpErr resource.CloudFoundryHTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting the autoscaling policy: %w", err)
+	}
+	defer resp.Body.Close()
+	var policy autoscalerPolicy
+	if err := json.NewDecoder(resp.Body).Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to decode the autoscaling policy: %w", err)
+	}
+	return policy.toAutoscalingPolicy(), nil
+}
+
+// discoverAutoscalingPolicy retrieves the scaling policy of an application bound to the App Autoscaler. Since the
+// policy lives outside the application, failing to retrieve it is reported as a warning instead of failing the
+// discovery.
+func (c *CloudFoundryProvider) discoverAutoscalingPolicy(appGUID string, env map[string]json.RawMessage) (*AutoscalingPolicy, []pTypes.Warning) {
+	if !isBoundToAutoscaler(env) {
+		return nil, nil
+	}
+	policy, err := c.getAutoscalingPolicy(appGUID)
+	if err != nil {
+		return nil, []pTypes.Warning{{Path: "autoscaling", Message: err.Error()}}
+	}
+	return policy, nil
+}
+
+// applyAutoscalingPolicy attaches the scaling policy to the web process. The policy of the App Autoscaler is defined
+// per application, without process type: its scaling engine only changes the instance count of the web process, and
+// its metrics are collected from the web process instances, so the other processes keep their fixed instance count.
+func applyAutoscalingPolicy(app *Application, policy *AutoscalingPolicy) []pTypes.Warning {
+	if policy == nil {
+		return nil
+	}
+	for i := range app.Processes {
+		if app.Processes[i].Type == Web {
+			app.Processes[i].Autoscaling = policy
+			return nil
+		}
+	}
+	return []pTypes.Warning{{Path: "autoscaling", Message: "the application is bound to the App Autoscaler but has no web process to scale"}}
+}
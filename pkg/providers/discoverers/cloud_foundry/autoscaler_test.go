@@ -0,0 +1,147 @@
+package cloud_foundry
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const autoscalerPolicyJSON = `{
+  "instance_min_count": 2,
+  "instance_max_count": 10,
+  "scaling_rules": [
+    {"metric_type": "cpuutil", "threshold": 80, "operator": ">=", "adjustment": "+2", "breach_duration_secs": 120, "cool_down_secs": 300},
+    {"metric_type": "throughput", "threshold": 50, "operator": "<", "adjustment": "-1"}
+  ],
+  "schedules": {
+    "timezone": "Europe/Madrid",
+    "recurring_schedule": [
+      {"start_time": "08:00", "end_time": "18:00", "days_of_week": [1, 2, 3, 4, 5], "instance_min_count": 4, "instance_max_count": 12, "initial_min_instance_count": 6}
+    ],
+    "specific_date": [
+      {"start_date_time": "2025-11-28T00:00", "end_date_time": "2025-11-29T23:59", "instance_min_count": 8, "instance_max_count": 20}
+    ]
+  }
+}`
+
+var _ = Describe("App Autoscaler policies", func() {
+	var (
+		stub  *httptest.Server
+		calls int
+	)
+
+	// startStub starts a local App Autoscaler API that returns the response for the policy of any application.
+	startStub := func(status int, body string) {
+		stub = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			Expect(r.Method).To(Equal(http.MethodGet))
+			Expect(r.URL.Path).To(MatchRegexp(`^/v1/apps/[^/]+/policy$`))
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+
+	discover := func(app cfTypes.AppManifest) (Application, []string) {
+		discovered, result := discoverLiveApplication(GlobalT, Config{AutoscalerURL: stub.URL}, false, app)
+		var warnings []string
+		for _, w := range result.Warnings {
+			warnings = append(warnings, w.Path+": "+w.Message)
+		}
+		return discovered, warnings
+	}
+
+	boundApp := func(services ...string) cfTypes.AppManifest {
+		instances := uint(2)
+		svcs := cfTypes.AppManifestServices{}
+		for _, s := range services {
+			svcs = append(svcs, cfTypes.AppManifestService{Name: s})
+		}
+		return cfTypes.AppManifest{
+			Name:     "app",
+			Metadata: &cfTypes.AppMetadata{},
+			Services: &svcs,
+			Processes: &cfTypes.AppManifestProcesses{
+				{Type: "web", Instances: &instances, Memory: "256", LogRateLimitPerSecond: "16"},
+				{Type: "worker", Instances: &instances, Memory: "256", LogRateLimitPerSecond: "16"},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		calls = 0
+	})
+
+	AfterEach(func() {
+		stub.Close()
+		testutil.Teardown()
+	})
+
+	It("attaches the policy of an application bound to the App Autoscaler to its web process", func() {
+		startStub(http.StatusOK, autoscalerPolicyJSON)
+		app, warnings := discover(boundApp("autoscaler", "mysql"))
+		Expect(warnings).To(BeEmpty())
+		Expect(app.Processes).To(HaveLen(2))
+		Expect(app.Processes[0].Type).To(Equal(Web))
+		Expect(app.Processes[0].Instances).To(Equal(2))
+		Expect(app.Processes[0].Autoscaling).To(Equal(&AutoscalingPolicy{
+			MinInstances: 2,
+			MaxInstances: 10,
+			Rules: []ScalingRule{
+				{MetricType: "cpuutil", Threshold: 80, Operator: ">=", Adjustment: "+2", BreachDurationSeconds: 120, CoolDownSeconds: 300},
+				{MetricType: "throughput", Threshold: 50, Operator: "<", Adjustment: "-1"},
+			},
+			Schedules: &ScalingSchedules{
+				Timezone: "Europe/Madrid",
+				Recurring: []RecurringSchedule{
+					{StartTime: "08:00", EndTime: "18:00", DaysOfWeek: []int{1, 2, 3, 4, 5}, MinInstances: 4, MaxInstances: 12, InitialMinInstances: 6},
+				},
+				SpecificDates: []SpecificDateSchedule{
+					{StartDateTime: "2025-11-28T00:00", EndDateTime: "2025-11-29T23:59", MinInstances: 8, MaxInstances: 20},
+				},
+			},
+		}))
+		Expect(app.Processes[1].Autoscaling).To(BeNil())
+	})
+
+	It("does not query the App Autoscaler for applications that are not bound to it", func() {
+		startStub(http.StatusOK, autoscalerPolicyJSON)
+		app, warnings := discover(boundApp("mysql"))
+		Expect(warnings).To(BeEmpty())
+		Expect(calls).To(Equal(0))
+		Expect(app.Processes[0].Autoscaling).To(BeNil())
+	})
+
+	It("does not set a policy when the bound application has none", func() {
+		startStub(http.StatusNotFound, `{"error": "policy not found"}`)
+		app, warnings := discover(boundApp("app-autoscaler"))
+		Expect(warnings).To(BeEmpty())
+		Expect(calls).To(Equal(1))
+		Expect(app.Processes[0].Autoscaling).To(BeNil())
+	})
+
+	It("reports a warning when the policy cannot be retrieved", func() {
+		startStub(http.StatusInternalServerError, `{"error": "internal error"}`)
+		app, warnings := discover(boundApp("autoscaler"))
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0]).To(HavePrefix("autoscaling: error getting the autoscaling policy"))
+		Expect(app.Processes[0].Autoscaling).To(BeNil())
+	})
+
+	DescribeTable("derives the App Autoscaler API URL from the Cloud Foundry API URL", func(apiURL, expected, expectedErr string) {
+		u, err := deriveAutoscalerURL(apiURL)
+		if expectedErr != "" {
+			Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+			return
+		}
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u).To(Equal(expected))
+	},
+		Entry("with an api host label", "https://api.sys.example.com", "https://autoscale.sys.example.com", ""),
+		Entry("with a path", "https://api.sys.example.com/v3", "https://autoscale.sys.example.com", ""),
+		Entry("without an api host label", "https://cf.example.com", "", "unable to derive the App Autoscaler API URL"),
+	)
+})
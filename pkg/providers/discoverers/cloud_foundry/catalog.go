@@ -0,0 +1,197 @@
+package cloud_foundry
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultImageCatalog contains the default mapping of the buildpacks and stacks to container images.
+//
+//go:embed image_catalog.yaml
+var defaultImageCatalog []byte
+
+// ImageCatalog maps the Cloud Foundry buildpacks and stacks to the container images and Cloud Native Buildpacks
+// recommended to build and run the applications in Kubernetes.
+type ImageCatalog struct {
+	// DefaultStack is the stack used to select the builder of the applications that do not define one.
+	DefaultStack string `yaml:"defaultStack,omitempty" json:"defaultStack,omitempty"`
+	// Stacks maps the stack names to their images.
+	Stacks map[string]StackImages `yaml:"stacks,omitempty" json:"stacks,omitempty"`
+	// Buildpacks contains the buildpack entries. The first entry matching a buildpack is used.
+	Buildpacks []BuildpackImages `yaml:"buildpacks,omitempty" json:"buildpacks,omitempty"`
+}
+
+// StackImages captures the images recommended for a Cloud Foundry stack.
+type StackImages struct {
+	// Builder is the Cloud Native Buildpacks builder based on the same operating system as the stack.
+	Builder string `yaml:"builder,omitempty" json:"builder,omitempty"`
+}
+
+// BuildpackImages captures the images recommended for the applications using a buildpack.
+type BuildpackImages struct {
+	// Name identifies the entry, e.g. `java`. An entry of an overriding catalog replaces the entry with the same name.
+	Name string `yaml:"name" json:"name"`
+	// Language is the runtime language detected by source inspection that selects this entry for the applications
+	// that do not define buildpacks.
+	Language RuntimeLanguage `yaml:"language,omitempty" json:"language,omitempty"`
+	// Match contains the glob patterns matching the buildpack names, URLs or image references, e.g.
+	// `java_buildpack` or `*java-buildpack*`.
+	Match []string `yaml:"match" json:"match"`
+	// BaseImage is the image recommended to run the applications.
+	BaseImage string `yaml:"baseImage,omitempty" json:"baseImage,omitempty"`
+	// BuildImage is the image recommended to build the applications in a multi-stage build.
+	BuildImage string `yaml:"buildImage,omitempty" json:"buildImage,omitempty"`
+	// Buildpacks are the Cloud Native Buildpacks that replace the buildpack.
+	Buildpacks []string `yaml:"buildpacks,omitempty" json:"buildpacks,omitempty"`
+}
+
+// DefaultImageCatalog returns the image catalog embedded in the library.
+func DefaultImageCatalog() (*ImageCatalog, error) {
+	var c ImageCatalog
+	if err := yaml.Unmarshal(defaultImageCatalog, &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the default image catalog: %w", err)
+	}
+	return &c, nil
+}
+
+// LoadImageCatalog returns the default image catalog overridden with the catalog in the file. The catalogs that can't be
+// parsed, or whose buildpack entries have no name or invalid match patterns, fail with ErrInvalidImageCatalog.
+func LoadImageCatalog(filePath string) (*ImageCatalog, error) {
+	c, err := DefaultImageCatalog()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image catalog: %w", err)
+	}
+	var o ImageCatalog
+	if err := yaml.Unmarshal(data, &o); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrInvalidImageCatalog, filePath, err)
+	}
+	for _, e := range o.Buildpacks {
+		if e.Name == "" || len(e.Match) == 0 {
+			return nil, fmt.Errorf("%w %s: buildpack entries require a name and match patterns", ErrInvalidImageCatalog, filePath)
+		}
+		for _, pattern := range e.Match {
+			// path.Match validates the whole pattern, even when it does not match the name
+			if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+				return nil, fmt.Errorf("%w %s: buildpack entry %s has an invalid match pattern %q", ErrInvalidImageCatalog, filePath, e.Name, pattern)
+			}
+		}
+	}
+	c.Override(o)
+	return c, nil
+}
+
+// Override replaces the stacks and buildpack entries of the catalog with the ones of the given catalog with the same
+// name. The new buildpack entries take precedence over the existing ones.
+func (c *ImageCatalog) Override(o ImageCatalog) {
+	if o.DefaultStack != "" {
+		c.DefaultStack = o.DefaultStack
+	}
+	for name, s := range o.Stacks {
+		if c.Stacks == nil {
+			c.Stacks = map[string]StackImages{}
+		}
+		c.Stacks[name] = s
+	}
+	var added []BuildpackImages
+	for _, e := range o.Buildpacks {
+		if i := slices.IndexFunc(c.Buildpacks, func(b BuildpackImages) bool { return b.Name == e.Name }); i >= 0 {
+			c.Buildpacks[i] = e
+			continue
+		}
+		added = append(added, e)
+	}
+	c.Buildpacks = append(added, c.Buildpacks...)
+}
+
+// Resolve returns the container images recommended for the application, or nil when the application runs a Docker
+// image or defines no buildpacks and its detected runtime is not in the catalog. The last buildpack, which provides
+// the start command in Cloud Foundry, selects the base image, and all the buildpacks contribute their Cloud Native
+// Buildpacks.
+func (c *ImageCatalog) Resolve(app Application) *ContainerImage {
+	if app.Docker.Image != "" {
+		return nil
+	}
+	img := &ContainerImage{}
+	var final *BuildpackImages
+	for _, bp := range app.BuildPacks {
+		final = c.match(bp)
+		if final == nil {
+			img.UnmappedBuildpacks = append(img.UnmappedBuildpacks, bp)
+			continue
+		}
+		for _, b := range final.Buildpacks {
+			if !slices.Contains(img.Buildpacks, b) {
+				img.Buildpacks = append(img.Buildpacks, b)
+			}
+		}
+	}
+	if len(app.BuildPacks) == 0 && app.Runtime != nil && app.Runtime.Language != "" {
+		if i := slices.IndexFunc(c.Buildpacks, func(b BuildpackImages) bool { return b.Language == app.Runtime.Language }); i >= 0 {
+			final = &c.Buildpacks[i]
+			img.Buildpacks = slices.Clone(final.Buildpacks)
+		}
+	}
+	if final == nil && len(img.Buildpacks) == 0 && len(img.UnmappedBuildpacks) == 0 {
+		return nil
+	}
+	if final != nil {
+		img.Entry, img.BaseImage, img.BuildImage = final.Name, final.BaseImage, final.BuildImage
+	}
+	stack := app.Stack
+	if stack == "" {
+		stack = c.DefaultStack
+	}
+	img.Builder = c.Stacks[stack].Builder
+	return img
+}
+
+// match returns the first entry matching the buildpack name, URL or image reference. The patterns of the loaded
+// catalogs are validated by LoadImageCatalog, so the errors of path.Match are not expected.
+func (c *ImageCatalog) match(buildpack string) *BuildpackImages {
+	candidates := buildpackCandidates(buildpack)
+	for i, e := range c.Buildpacks {
+		for _, pattern := range e.Match {
+			for _, candidate := range candidates {
+				if ok, _ := path.Match(strings.ToLower(pattern), candidate); ok {
+					return &c.Buildpacks[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// buildpackCandidates normalizes the buildpack reference and returns it with each of its path suffixes, so that the
+// patterns match the names (`java_buildpack`), the git URLs (`https://github.com/cloudfoundry/java-buildpack.git#v4.6`)
+// and the image references (`docker://gcr.io/paketo-buildpacks/java:10.0`).
+func buildpackCandidates(buildpack string) []string {
+	ref := strings.ToLower(strings.TrimSpace(buildpack))
+	ref, _, _ = strings.Cut(ref, "#")
+	if _, after, ok := strings.Cut(ref, "://"); ok {
+		ref = after
+	}
+	ref = strings.TrimPrefix(ref, "git@")
+	ref = strings.TrimSuffix(strings.TrimSuffix(ref, "/"), ".git")
+	// Remove the tag or digest of image references
+	ref, _, _ = strings.Cut(ref, "@sha256:")
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	candidates := []string{ref}
+	for i, r := range ref {
+		if r == '/' || r == ':' {
+			candidates = append(candidates, ref[i+1:])
+		}
+	}
+	return candidates
+}
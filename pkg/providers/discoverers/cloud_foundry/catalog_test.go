@@ -0,0 +1,130 @@
+package cloud_foundry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image catalog", func() {
+	var catalog *ImageCatalog
+
+	BeforeEach(func() {
+		var err error
+		catalog, err = DefaultImageCatalog()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("matches the buildpack references", func(buildpack, entry string) {
+		img := catalog.Resolve(Application{BuildPacks: []string{buildpack}})
+		Expect(img).NotTo(BeNil())
+		Expect(img.Entry).To(Equal(entry))
+	},
+		Entry("with a system buildpack name", "java_buildpack", "java"),
+		Entry("with an offline buildpack name", "java_buildpack_offline", "java"),
+		Entry("with a git URL and a tag", "https://github.com/cloudfoundry/java-buildpack.git#v4.50", "java"),
+		Entry("with a SSH git URL", "git@github.com:cloudfoundry/nodejs-buildpack.git", "nodejs"),
+		Entry("with a Paketo buildpack image", "docker://gcr.io/paketo-buildpacks/python:2.1.0", "python"),
+		Entry("with a Paketo buildpack id", "paketo-buildpacks/java-native-image", "java"),
+		Entry("with an uppercase name", "Go_Buildpack", "go"),
+	)
+
+	It("selects the base image with the last buildpack and collects the buildpacks of all of them", func() {
+		img := catalog.Resolve(Application{
+			BuildPacks: []string{"nodejs_buildpack", "https://example.com/custom-buildpack.zip", "python_buildpack"},
+			Stack:      "cflinuxfs3",
+		})
+		Expect(img).To(Equal(&ContainerImage{
+			Entry:              "python",
+			BaseImage:          "registry.access.redhat.com/ubi9/python-311",
+			Builder:            "paketobuildpacks/builder:base",
+			Buildpacks:         []string{"paketo-buildpacks/nodejs", "paketo-buildpacks/python"},
+			UnmappedBuildpacks: []string{"https://example.com/custom-buildpack.zip"},
+		}))
+	})
+
+	It("uses the detected runtime when the application does not define buildpacks", func() {
+		img := catalog.Resolve(Application{Runtime: &Runtime{Language: JavaRuntimeLanguage}})
+		Expect(img).NotTo(BeNil())
+		Expect(img.Entry).To(Equal("java"))
+		Expect(img.BuildImage).To(Equal("registry.access.redhat.com/ubi9/openjdk-17"))
+		Expect(img.Builder).To(Equal("paketobuildpacks/builder-jammy-base"))
+	})
+
+	It("does not resolve images for Docker applications and reports unknown buildpacks", func() {
+		Expect(catalog.Resolve(Application{Docker: Docker{Image: "nginx"}, BuildPacks: []string{"java_buildpack"}})).To(BeNil())
+		Expect(catalog.Resolve(Application{})).To(BeNil())
+		Expect(catalog.Resolve(Application{BuildPacks: []string{"unknown"}})).To(Equal(&ContainerImage{
+			Builder:            "paketobuildpacks/builder-jammy-base",
+			UnmappedBuildpacks: []string{"unknown"},
+		}))
+	})
+
+	When("loading a catalog file", func() {
+		var file string
+
+		BeforeEach(func() {
+			file = filepath.Join(GinkgoT().TempDir(), "catalog.yaml")
+			Expect(os.WriteFile(file, []byte(`defaultStack: custom
+stacks:
+  custom:
+    builder: registry.example.com/builder
+buildpacks:
+  - name: java
+    match: [java_buildpack]
+    baseImage: registry.example.com/java:21
+  - name: company
+    match: ["*company-buildpack*"]
+    baseImage: registry.example.com/company
+`), 0644)).To(Succeed())
+		})
+
+		It("overrides the default entries and gives precedence to the new ones", func() {
+			c, err := LoadImageCatalog(file)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Resolve(Application{BuildPacks: []string{"java_buildpack"}})).To(Equal(&ContainerImage{
+				Entry:     "java",
+				BaseImage: "registry.example.com/java:21",
+				Builder:   "registry.example.com/builder",
+			}))
+			Expect(c.Resolve(Application{BuildPacks: []string{"https://git.example.com/company-buildpack"}}).BaseImage).To(Equal("registry.example.com/company"))
+			Expect(c.Resolve(Application{BuildPacks: []string{"nodejs_buildpack"}, Stack: "cflinuxfs4"}).Builder).To(Equal("paketobuildpacks/builder-jammy-base"))
+		})
+
+		It("exposes the resolved images on the discovered application", func() {
+			logger := logr.New(logr.Discard().GetSink())
+			p, err := New(&Config{ManifestPath: filepath.Join("test_data", "spring-music", "manifest.yml"), ImageCatalogPath: file}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			result, err := p.Discover(AppReference{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Content["spec"]).To(HaveKey("containerImage"))
+			Expect(result.Content["spec"]).To(HaveKeyWithValue("containerImage", HaveKeyWithValue("baseImage", "registry.example.com/java:21")))
+		})
+
+		It("rejects entries without match patterns", func() {
+			Expect(os.WriteFile(file, []byte("buildpacks:\n  - name: java\n"), 0644)).To(Succeed())
+			_, err := LoadImageCatalog(file)
+			Expect(err).To(MatchError(ContainSubstring("buildpack entries require a name and match patterns")))
+			Expect(err).To(MatchError(ErrInvalidImageCatalog))
+		})
+
+		It("rejects invalid match patterns", func() {
+			Expect(os.WriteFile(file, []byte("buildpacks:\n  - name: java\n    match: [\"java_[buildpack\"]\n"), 0644)).To(Succeed())
+			_, err := LoadImageCatalog(file)
+			Expect(err).To(MatchError(ErrInvalidImageCatalog))
+			Expect(err).To(MatchError(ContainSubstring(`buildpack entry java has an invalid match pattern "java_[buildpack"`)))
+		})
+
+		It("rejects catalogs that are not valid YAML", func() {
+			Expect(os.WriteFile(file, []byte("buildpacks: [\n"), 0644)).To(Succeed())
+			_, err := LoadImageCatalog(file)
+			Expect(err).To(MatchError(ErrInvalidImageCatalog))
+			var parseErr *ManifestParseError
+			Expect(errors.As(err, &parseErr)).To(BeFalse())
+		})
+	})
+})
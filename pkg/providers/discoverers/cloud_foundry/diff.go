@@ -0,0 +1,298 @@
+package cloud_foundry
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
+)
+
+// ChangeType describes how a field changed between two discoveries.
+type ChangeType string
+
+const (
+	AddedChangeType    ChangeType = "added"
+	RemovedChangeType  ChangeType = "removed"
+	ModifiedChangeType ChangeType = "modified"
+)
+
+// Change captures the difference of a single field between two discoveries.
+type Change struct {
+	// Path identifies the field using the JSON names of the discovery content. List items are identified by their
+	// identity instead of their position, e.g. `services[db].bindingName` or `processes[web].instances`.
+	Path string `json:"path"`
+	// Type captures whether the field was added, removed or modified.
+	Type ChangeType `json:"type"`
+	// Old contains the value in the previous discovery. Empty when the field was added.
+	Old any `json:"old,omitempty"`
+	// New contains the value in the current discovery. Empty when the field was removed.
+	New any `json:"new,omitempty"`
+}
+
+// ChangeSet contains the changes between two discoveries, sorted by path.
+type ChangeSet struct {
+	Changes []Change `json:"changes"`
+}
+
+// IsEmpty returns true when both discoveries are equivalent.
+func (cs ChangeSet) IsEmpty() bool {
+	return len(cs.Changes) == 0
+}
+
+// String renders the change set as text, one change per line with `+` for added fields, `-` for removed fields and
+// `~` for modified fields.
+func (cs ChangeSet) String() string {
+	var sb strings.Builder
+	for _, c := range cs.Changes {
+		switch c.Type {
+		case AddedChangeType:
+			fmt.Fprintf(&sb, "+ %s: %s\n", c.Path, renderValue(c.New))
+		case RemovedChangeType:
+			fmt.Fprintf(&sb, "- %s: %s\n", c.Path, renderValue(c.Old))
+		case ModifiedChangeType:
+			fmt.Fprintf(&sb, "~ %s: %s -> %s\n", c.Path, renderValue(c.Old), renderValue(c.New))
+		}
+	}
+	return sb.String()
+}
+
+// JSON renders the change set as JSON.
+func (cs ChangeSet) JSON() ([]byte, error) {
+	if cs.Changes == nil {
+		cs.Changes = []Change{}
+	}
+	return json.MarshalIndent(cs, "", "  ")
+}
+
+func renderValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// listIdentities maps the name of the lists in the discovery content to the field that identifies their items.
+// Lists not included here are compared as a whole.
+var listIdentities = map[string]string{
+	"services":  "name",
+	"processes": "type",
+	"routes":    "route",
+	"sidecars":  "name",
+}
+
+// ignoredPaths contains the fields that change on every discovery and are not relevant to detect drift.
+var ignoredPaths = map[string]bool{
+	"metadata.discoveredAt": true,
+}
+
+// secretReference matches the references to sensitive information that replace the concealed values.
+var secretReference = regexp.MustCompile(`^\$\(([^)]+)\)$`)
+
+// sensitiveValue holds a concealed value together with the reference that replaces it in the discovery content. The
+// values are compared to detect changes but only the references are reported.
+type sensitiveValue struct {
+	ref   string
+	value any
+}
+
+// DiffApplications returns the changes between two discovered applications.
+func DiffApplications(old, new Application) (ChangeSet, error) {
+	o, err := structToMap(old)
+	if err != nil {
+		return ChangeSet{}, fmt.Errorf("failed to convert the previous application: %w", err)
+	}
+	n, err := structToMap(new)
+	if err != nil {
+		return ChangeSet{}, fmt.Errorf("failed to convert the current application: %w", err)
+	}
+	return diffContent(o, n), nil
+}
+
+// DiffDiscoverResults returns the changes between the content of two discovery results. The references to concealed
+// sensitive information are resolved with the secrets of each result, so that only the values are compared and the
+// randomly generated references do not show as changes.
+func DiffDiscoverResults(old, new *pTypes.DiscoverResult) (ChangeSet, error) {
+	o, err := structToMap(old.Content)
+	if err != nil {
+		return ChangeSet{}, fmt.Errorf("failed to convert the previous discovery content: %w", err)
+	}
+	n, err := structToMap(new.Content)
+	if err != nil {
+		return ChangeSet{}, fmt.Errorf("failed to convert the current discovery content: %w", err)
+	}
+	o = resolveSecrets(o, old.Secret).(map[string]any)
+	n = resolveSecrets(n, new.Secret).(map[string]any)
+	return diffContent(o, n), nil
+}
+
+// resolveSecrets replaces the references to concealed values in v with their sensitiveValue.
+func resolveSecrets(v any, secrets map[string]any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, e := range t {
+			m[k] = resolveSecrets(e, secrets)
+		}
+		return m
+	case []any:
+		l := make([]any, len(t))
+		for i, e := range t {
+			l[i] = resolveSecrets(e, secrets)
+		}
+		return l
+	case string:
+		if m := secretReference.FindStringSubmatch(t); m != nil {
+			if s, ok := secrets[m[1]]; ok {
+				return sensitiveValue{ref: t, value: s}
+			}
+		}
+	}
+	return v
+}
+
+func diffContent(old, new map[string]any) ChangeSet {
+	var changes []Change
+	diffMaps("", old, new, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return ChangeSet{Changes: changes}
+}
+
+func diffMaps(path string, old, new map[string]any, changes *[]Change) {
+	for k, o := range old {
+		p := joinPath(path, k)
+		if ignoredPaths[p] {
+			continue
+		}
+		n, ok := new[k]
+		if !ok {
+			*changes = append(*changes, Change{Path: p, Type: RemovedChangeType, Old: reported(o)})
+			continue
+		}
+		diffValues(p, k, o, n, changes)
+	}
+	for k, n := range new {
+		p := joinPath(path, k)
+		if _, ok := old[k]; ok || ignoredPaths[p] {
+			continue
+		}
+		*changes = append(*changes, Change{Path: p, Type: AddedChangeType, New: reported(n)})
+	}
+}
+
+func diffValues(path, key string, old, new any, changes *[]Change) {
+	switch o := old.(type) {
+	case map[string]any:
+		if n, ok := new.(map[string]any); ok {
+			diffMaps(path, o, n, changes)
+			return
+		}
+	case []any:
+		if n, ok := new.([]any); ok {
+			if id, ok := listIdentities[key]; ok && identifiable(o, id) && identifiable(n, id) {
+				diffLists(path, id, o, n, changes)
+				return
+			}
+		}
+	}
+	if !equalValues(old, new) {
+		*changes = append(*changes, Change{Path: path, Type: ModifiedChangeType, Old: reported(old), New: reported(new)})
+	}
+}
+
+// diffLists matches the items of both lists by the value of their identity field and compares the matched items.
+func diffLists(path, id string, old, new []any, changes *[]Change) {
+	oldItems := indexBy(old, id)
+	newItems := indexBy(new, id)
+	for k, o := range oldItems {
+		p := fmt.Sprintf("%s[%s]", path, k)
+		n, ok := newItems[k]
+		if !ok {
+			*changes = append(*changes, Change{Path: p, Type: RemovedChangeType, Old: reported(o)})
+			continue
+		}
+		diffMaps(p, o, n, changes)
+	}
+	for k, n := range newItems {
+		if _, ok := oldItems[k]; ok {
+			continue
+		}
+		*changes = append(*changes, Change{Path: fmt.Sprintf("%s[%s]", path, k), Type: AddedChangeType, New: reported(n)})
+	}
+}
+
+// identifiable returns true when all the items of the list are objects with a unique value in the identity field.
+func identifiable(l []any, id string) bool {
+	seen := map[string]bool{}
+	for _, e := range l {
+		m, ok := e.(map[string]any)
+		if !ok {
+			return false
+		}
+		k, ok := m[id].(string)
+		if !ok || seen[k] {
+			return false
+		}
+		seen[k] = true
+	}
+	return true
+}
+
+func indexBy(l []any, id string) map[string]map[string]any {
+	m := make(map[string]map[string]any, len(l))
+	for _, e := range l {
+		item := e.(map[string]any)
+		m[item[id].(string)] = item
+	}
+	return m
+}
+
+// equalValues compares two values using the concealed values instead of their references.
+func equalValues(old, new any) bool {
+	return reflect.DeepEqual(revealed(old), revealed(new))
+}
+
+// revealed returns v with the sensitive values replaced by the concealed value.
+func revealed(v any) any {
+	return replaceSensitive(v, func(s sensitiveValue) any { return s.value })
+}
+
+// reported returns v with the sensitive values replaced by their reference, so that concealed values are never
+// included in the change set.
+func reported(v any) any {
+	return replaceSensitive(v, func(s sensitiveValue) any { return s.ref })
+}
+
+func replaceSensitive(v any, replace func(sensitiveValue) any) any {
+	switch t := v.(type) {
+	case sensitiveValue:
+		return replace(t)
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, e := range t {
+			m[k] = replaceSensitive(e, replace)
+		}
+		return m
+	case []any:
+		l := make([]any, len(t))
+		for i, e := range t {
+			l[i] = replaceSensitive(e, replace)
+		}
+		return l
+	}
+	return v
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
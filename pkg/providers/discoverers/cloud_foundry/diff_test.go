@@ -0,0 +1,119 @@
+package cloud_foundry
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Detecting drift between discoveries", func() {
+	var (
+		logger = logr.New(logr.Discard().GetSink())
+		app    Application
+	)
+
+	BeforeEach(func() {
+		app = Application{
+			Metadata: Metadata{Name: "app", Space: "space"},
+			Env:      map[string]string{"FOO": "bar"},
+			Routes: RouteSpec{Routes: Routes{
+				{Route: "app.example.com"},
+				{Route: "app.internal.example.com"},
+			}},
+			Services: Services{{Name: "db"}, {Name: "cache"}},
+			Processes: Processes{
+				{Type: Worker, ProcessSpecTemplate: ProcessSpecTemplate{Instances: 1, Memory: "1G"}},
+				{Type: Web, ProcessSpecTemplate: ProcessSpecTemplate{Instances: 2, Memory: "1G"}},
+			},
+		}
+	})
+
+	It("returns an empty change set for equivalent applications", func() {
+		current := app
+		current.Services = Services{{Name: "cache"}, {Name: "db"}}
+		current.Processes = Processes{app.Processes[1], app.Processes[0]}
+		cs, err := DiffApplications(app, current)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cs.IsEmpty()).To(BeTrue())
+		Expect(cs.String()).To(BeEmpty())
+	})
+
+	It("matches list items by identity instead of position", func() {
+		current := app
+		current.Env = map[string]string{"FOO": "bar", "BAZ": "qux"}
+		current.Routes = RouteSpec{Routes: Routes{{Route: "app.internal.example.com"}}}
+		current.Services = Services{{Name: "cache"}, {Name: "db", BindingName: "database"}, {Name: "queue"}}
+		current.Processes = Processes{
+			{Type: Web, ProcessSpecTemplate: ProcessSpecTemplate{Instances: 4, Memory: "1G"}},
+			app.Processes[0],
+		}
+
+		cs, err := DiffApplications(app, current)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cs.Changes).To(Equal([]Change{
+			{Path: "env.BAZ", Type: AddedChangeType, New: "qux"},
+			{Path: "processes[web].instances", Type: ModifiedChangeType, Old: float64(2), New: float64(4)},
+			{Path: "routes.routes[app.example.com]", Type: RemovedChangeType, Old: map[string]any{"route": "app.example.com", "options": map[string]any{}}},
+			{Path: "services[db].bindingName", Type: AddedChangeType, New: "database"},
+			{Path: "services[queue]", Type: AddedChangeType, New: map[string]any{"name": "queue"}},
+		}))
+		Expect(cs.String()).To(Equal(`+ env.BAZ: qux
+~ processes[web].instances: 2 -> 4
+- routes.routes[app.example.com]: {"options":{},"route":"app.example.com"}
++ services[db].bindingName: database
++ services[queue]: {"name":"queue"}
+`))
+
+		b, err := cs.JSON()
+		Expect(err).NotTo(HaveOccurred())
+		var received ChangeSet
+		Expect(json.Unmarshal(b, &received)).To(Succeed())
+		Expect(received).To(Equal(cs))
+	})
+
+	It("compares the concealed values instead of their references", func() {
+		manifest := filepath.Join("test_data", "inline-process-with-type-only-manifest", "manifest.yml")
+		p, err := New(&Config{ManifestPath: manifest}, &logger, true)
+		Expect(err).NotTo(HaveOccurred())
+		previous, err := p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		current, err := p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(previous.Secret).NotTo(BeEmpty())
+		Expect(previous.Secret).NotTo(Equal(current.Secret))
+
+		cs, err := DiffDiscoverResults(previous, current)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cs.IsEmpty()).To(BeTrue())
+
+		By("changing a concealed value")
+		for k := range current.Secret {
+			current.Secret[k] = "changed"
+		}
+		cs, err = DiffDiscoverResults(previous, current)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cs.Changes).NotTo(BeEmpty())
+		Expect(cs.String()).NotTo(ContainSubstring("changed"))
+		for _, c := range cs.Changes {
+			Expect(c.Type).To(Equal(ModifiedChangeType))
+			Expect(c.Old).To(MatchRegexp(`^\$\(.+\)$`))
+			Expect(c.New).To(MatchRegexp(`^\$\(.+\)$`))
+		}
+	})
+
+	It("ignores the discovery time of versioned documents", func() {
+		manifest := filepath.Join("test_data", "basic-app", "manifest.yml")
+		p, err := New(&Config{ManifestPath: manifest}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		previous, err := p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		current, err := p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		cs, err := DiffDiscoverResults(previous, current)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cs.IsEmpty()).To(BeTrue())
+	})
+})
@@ -0,0 +1,70 @@
+package cloud_foundry
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+)
+
+// dockerHubRegistry is the registry of the images whose reference has no registry host, e.g. `nginx:1.27`.
+const dockerHubRegistry = "docker.io"
+
+// publicRegistries are the registries that serve their images without credentials.
+var publicRegistries = []string{
+	dockerHubRegistry,
+	"index.docker.io",
+	"registry-1.docker.io",
+	"public.ecr.aws",
+	"mcr.microsoft.com",
+	"registry.k8s.io",
+	"registry.access.redhat.com",
+}
+
+// imageRegistry returns the host of the registry of the image reference, e.g. `registry.example.com:5000` for
+// `registry.example.com:5000/team/app:1.0`. The references without registry host are pulled from Docker Hub.
+func imageRegistry(image string) string {
+	host, _, ok := strings.Cut(image, "/")
+	if !ok || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		return dockerHubRegistry
+	}
+	return strings.ToLower(host)
+}
+
+// completeDockerRegistry sets the registry of the image and whether pulling it requires credentials: when the
+// registry is not a public one, or when the application declares a registry username.
+func completeDockerRegistry(d *Docker) {
+	if d.Image == "" {
+		return
+	}
+	d.Registry = imageRegistry(d.Image)
+	d.PrivateRegistry = d.Username != "" || !slices.Contains(publicRegistries, d.Registry)
+}
+
+// getDockerUsername retrieves the username of the registry of a live docker application. The Cloud Controller keeps
+// it in the docker packages of the application, along with the image, and never returns the password. It returns the
+// username of the most recent package that is ready, or an empty string when the image is pulled anonymously.
+func (c *CloudFoundryProvider) getDockerUsername(appGUID string) (string, error) {
+	opts := client.NewPackageListOptions()
+	opts.Types.EqualTo("docker")
+	opts.States.EqualTo(string(resource.PackageStateReady))
+	packages, err := c.cli.Packages.ListForAppAll(context.Background(), appGUID, opts)
+	if isNotFoundError(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var latest *resource.Package
+	for _, p := range packages {
+		if latest == nil || p.CreatedAt.After(latest.CreatedAt) {
+			latest = p
+		}
+	}
+	if latest == nil || latest.Data.Docker == nil || latest.Data.Docker.DockerCredentials == nil {
+		return "", nil
+	}
+	return latest.Data.Docker.Username, nil
+}
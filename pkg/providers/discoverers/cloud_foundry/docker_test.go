@@ -0,0 +1,92 @@
+package cloud_foundry
+
+import (
+	"github.com/cloudfoundry/go-cfclient/v3/config"
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	"github.com/go-logr/logr"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Docker registry discovery", func() {
+
+	DescribeTable("resolves the registry of the image", func(image, registry string) {
+		Expect(imageRegistry(image)).To(Equal(registry))
+	},
+		Entry("an official image", "nginx:1.27", "docker.io"),
+		Entry("an image of a Docker Hub user", "team/app:1.0", "docker.io"),
+		Entry("an image of a registry with a port", "registry.example.com:5000/team/app:1.0", "registry.example.com:5000"),
+		Entry("an image of a local registry", "localhost/app", "localhost"),
+		Entry("an image of a registry in upper case", "Registry.Example.com/app", "registry.example.com"),
+	)
+
+	DescribeTable("detects the images pulled with credentials", func(docker Docker, private bool) {
+		completeDockerRegistry(&docker)
+		Expect(docker.PrivateRegistry).To(Equal(private))
+	},
+		Entry("a Docker Hub image", Docker{Image: "nginx"}, false),
+		Entry("an image of a public registry", Docker{Image: "mcr.microsoft.com/dotnet/aspnet:8.0"}, false),
+		Entry("a Docker Hub image with a username", Docker{Image: "team/app", Username: "team"}, true),
+		Entry("an image of another registry", Docker{Image: "registry.example.com/app"}, true),
+	)
+
+	It("leaves the applications without image unchanged", func() {
+		docker := Docker{}
+		completeDockerRegistry(&docker)
+		Expect(docker).To(Equal(Docker{}))
+	})
+
+	Context("when performing live discovery", func() {
+		AfterEach(func() {
+			testutil.Teardown()
+		})
+
+		It("conceals the username of the docker package", func() {
+			app, result := discoverLiveApplication(GlobalT, Config{}, true, cfTypes.AppManifest{
+				Name:     "app",
+				Metadata: &cfTypes.AppMetadata{},
+				Docker:   &cfTypes.AppManifestDocker{Image: "registry.example.com/team/app:1.0", Username: "registry-user"},
+			})
+			Expect(app.Docker.Registry).To(Equal("registry.example.com"))
+			Expect(app.Docker.PrivateRegistry).To(BeTrue())
+			Expect(app.Docker.Username).To(MatchRegexp(`^\$\(.+\)$`))
+			Expect(result.Secret).To(ContainElement("registry-user"))
+		})
+
+		It("reports the username that can't be retrieved", func() {
+			app, result := discoverLiveApplication(GlobalT, Config{}, false, cfTypes.AppManifest{
+				Name:     "app",
+				Metadata: &cfTypes.AppMetadata{},
+				Docker:   &cfTypes.AppManifestDocker{Image: "registry.example.com/team/app:1.0", Username: "registry-user"},
+			}, withForbidden("packages"))
+			Expect(app.Docker.Image).To(Equal("registry.example.com/team/app:1.0"))
+			Expect(app.Docker.Username).To(BeEmpty())
+			Expect(result.Warnings).To(ConsistOf(HaveField("Path", "docker.username")))
+		})
+
+		discover := func(opts ...mockOption) error {
+			m, serverURL := newMockApplication(cfTypes.AppManifest{
+				Name:     "app",
+				Metadata: &cfTypes.AppMetadata{},
+				Docker:   &cfTypes.AppManifestDocker{Image: "registry.example.com/team/app:1.0"},
+			}, GlobalT, opts...)
+			cfg, err := config.New(serverURL, config.Token("", "fake-refresh-token"), config.SkipTLSValidation())
+			Expect(err).NotTo(HaveOccurred())
+			logger := logr.Discard()
+			p, err := New(&Config{CloudFoundryConfig: cfg}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = p.Discover(AppReference{OrgName: m.organization().Name, SpaceName: m.space().Name, AppName: m.application().Name})
+			return err
+		}
+
+		It("returns ErrAuthentication when the droplet can't be retrieved", func() {
+			Expect(discover(withForbidden("droplets/current"))).To(MatchError(ErrAuthentication))
+		})
+
+		It("fails when the current droplet has no image", func() {
+			Expect(discover(withDroplet(resource.Droplet{}, nil))).To(MatchError(ContainSubstring("the current droplet of docker app app has no image")))
+		})
+	})
+})
@@ -0,0 +1,116 @@
+package cloud_foundry
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DiscoveryAPIVersion is the current version of the discovery document.
+	DiscoveryAPIVersion = "discovery.konveyor.io/v1alpha1"
+	// ApplicationKind is the kind of the discovery document containing a Cloud Foundry application.
+	ApplicationKind = "CloudFoundryApplication"
+
+	// LiveDiscoverySource identifies documents discovered from a live Cloud Foundry foundation.
+	LiveDiscoverySource = "live"
+	// LocalDiscoverySource identifies documents discovered from local manifest files.
+	LocalDiscoverySource = "local"
+)
+
+// DiscoveryDocument wraps a discovered application with the version of its format and the provenance of the data,
+// so that changes to the Application structure can be detected and stored documents upgraded.
+type DiscoveryDocument struct {
+	// APIVersion captures the version of the document format.
+	APIVersion string `yaml:"apiVersion" json:"apiVersion"`
+	// Kind captures the type of document. Only `CloudFoundryApplication` is supported.
+	Kind string `yaml:"kind" json:"kind"`
+	// Metadata captures the provenance of the discovered application.
+	Metadata DocumentMetadata `yaml:"metadata" json:"metadata"`
+	// Spec contains the discovered application.
+	Spec Application `yaml:"spec" json:"spec"`
+}
+
+// DocumentMetadata captures where and when an application was discovered.
+type DocumentMetadata struct {
+	// Source captures whether the application was discovered from a live foundation (`live`) or from a local
+	// manifest (`local`).
+	Source string `yaml:"source,omitempty" json:"source,omitempty"`
+	// APIEndpoint captures the Cloud Foundry API endpoint used for live discovery.
+	APIEndpoint string `yaml:"apiEndpoint,omitempty" json:"apiEndpoint,omitempty"`
+	// Organization captures the organization of the application for live discovery.
+	Organization string `yaml:"organization,omitempty" json:"organization,omitempty"`
+	// Space captures the space of the application for live discovery.
+	Space string `yaml:"space,omitempty" json:"space,omitempty"`
+	// ManifestPath captures the manifest file the application was discovered from for local discovery.
+	ManifestPath string `yaml:"manifestPath,omitempty" json:"manifestPath,omitempty"`
+	// Archive captures the archive containing the manifest, when the manifest was discovered inside an archive.
+	Archive string `yaml:"archive,omitempty" json:"archive,omitempty"`
+	// ArchiveEntry captures the path of the manifest relative to the root of the archive.
+	ArchiveEntry string `yaml:"archiveEntry,omitempty" json:"archiveEntry,omitempty"`
+	// DiscoveredAt captures the time of the discovery. It is omitted when the time is not known, e.g. for the
+	// documents converted from the bare application content.
+	DiscoveredAt *time.Time `yaml:"discoveredAt,omitempty" json:"discoveredAt,omitempty"`
+}
+
+// NewDiscoveryDocument wraps the application in a discovery document of the current version.
+func NewDiscoveryDocument(app Application, metadata DocumentMetadata) DiscoveryDocument {
+	return DiscoveryDocument{
+		APIVersion: DiscoveryAPIVersion,
+		Kind:       ApplicationKind,
+		Metadata:   metadata,
+		Spec:       app,
+	}
+}
+
+// documentConverter upgrades a document in its map representation to the next version.
+type documentConverter struct {
+	// to is the version produced by the converter.
+	to      string
+	convert func(doc map[string]any) (map[string]any, error)
+}
+
+// documentConverters contains the converters keyed by the version they upgrade from. Documents without version are
+// the bare application content produced before the envelope was introduced.
+var documentConverters = map[string]documentConverter{
+	"": {to: DiscoveryAPIVersion, convert: convertUnversionedDocument},
+}
+
+// ConvertDocument upgrades a stored discovery document, as decoded from its JSON or YAML representation, to the
+// current version. Documents without `apiVersion` are considered to be the bare application content returned by
+// discovery before the envelope was introduced.
+func ConvertDocument(doc map[string]any) (*DiscoveryDocument, error) {
+	version, _ := doc["apiVersion"].(string)
+	for version != DiscoveryAPIVersion {
+		c, ok := documentConverters[version]
+		if !ok {
+			return nil, fmt.Errorf("unsupported discovery document version %q", version)
+		}
+		var err error
+		doc, err = c.convert(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert discovery document from version %q to %q: %w", version, c.to, err)
+		}
+		version = c.to
+	}
+	if kind, _ := doc["kind"].(string); kind != ApplicationKind {
+		return nil, fmt.Errorf("unsupported discovery document kind %q", kind)
+	}
+	d, err := marshalUnmarshal[DiscoveryDocument](doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode discovery document: %w", err)
+	}
+	return &d, nil
+}
+
+// convertUnversionedDocument wraps the bare application content in the v1alpha1 envelope.
+func convertUnversionedDocument(doc map[string]any) (map[string]any, error) {
+	if _, ok := doc["name"]; !ok {
+		return nil, fmt.Errorf("missing application name")
+	}
+	return map[string]any{
+		"apiVersion": DiscoveryAPIVersion,
+		"kind":       ApplicationKind,
+		"metadata":   map[string]any{},
+		"spec":       doc,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package cloud_foundry
+
+import (
+	"path/filepath"
+
+	"github.com/cloudfoundry/go-cfclient/v3/config"
+	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	"github.com/go-logr/logr"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Versioned discovery documents", func() {
+	var (
+		logger   = logr.New(logr.Discard().GetSink())
+		manifest = filepath.Join("test_data", "complete-manifest", "manifest.yml")
+	)
+
+	When("discovering an application", func() {
+		AfterEach(func() {
+			testutil.Teardown()
+		})
+
+		It("wraps a locally discovered application in the envelope", func() {
+			p, err := New(&Config{ManifestPath: manifest}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			result, err := p.Discover(AppReference{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Content).To(HaveKeyWithValue("apiVersion", DiscoveryAPIVersion))
+			Expect(result.Content).To(HaveKeyWithValue("kind", ApplicationKind))
+			Expect(result.Content["metadata"]).To(HaveKeyWithValue("source", LocalDiscoverySource))
+			Expect(result.Content["metadata"]).To(HaveKeyWithValue("manifestPath", manifest))
+			Expect(result.Content["metadata"]).To(HaveKey("discoveredAt"))
+			Expect(result.Content["spec"]).To(HaveKeyWithValue("name", "complete"))
+		})
+
+		It("captures the foundation, organization and space of a live discovered application", func() {
+			m, serverURL := newMockApplication(cfTypes.AppManifest{Name: "app", Metadata: &cfTypes.AppMetadata{}}, GlobalT)
+			cfg, err := config.New(serverURL, config.Token("", "fake-refresh-token"), config.SkipTLSValidation())
+			Expect(err).NotTo(HaveOccurred())
+			p, err := New(&Config{CloudFoundryConfig: cfg}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			result, err := p.Discover(AppReference{OrgName: m.organization().Name, SpaceName: m.space().Name, AppName: m.application().Name})
+			Expect(err).NotTo(HaveOccurred())
+			doc, err := ConvertDocument(result.Content)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(doc.Metadata.Source).To(Equal(LiveDiscoverySource))
+			Expect(doc.Metadata.APIEndpoint).To(Equal(serverURL))
+			Expect(doc.Metadata.Organization).To(Equal(m.organization().Name))
+			Expect(doc.Metadata.Space).To(Equal(m.space().Name))
+			Expect(doc.Spec.Name).To(Equal(m.application().Name))
+		})
+	})
+
+	Describe("converting stored documents", func() {
+		It("upgrades an unversioned document to the current version", func() {
+			p, err := New(&Config{ManifestPath: manifest, BareOutput: true}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			result, err := p.Discover(AppReference{})
+			Expect(err).NotTo(HaveOccurred())
+			app, _, err := p.discoverFromManifestFile(manifest)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("storing the content as YAML and loading it back")
+			b, err := yaml.Marshal(result.Content)
+			Expect(err).NotTo(HaveOccurred())
+			var stored map[string]any
+			Expect(yaml.Unmarshal(b, &stored)).To(Succeed())
+
+			doc, err := ConvertDocument(stored)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(doc.APIVersion).To(Equal(DiscoveryAPIVersion))
+			Expect(doc.Kind).To(Equal(ApplicationKind))
+			Expect(doc.Metadata).To(Equal(DocumentMetadata{}))
+			Expect(doc.Spec).To(Equal(*app))
+		})
+
+		It("returns the document unchanged when it is already in the current version", func() {
+			doc := NewDiscoveryDocument(Application{Metadata: Metadata{Name: "app"}}, DocumentMetadata{Source: LocalDiscoverySource})
+			m, err := structToMap(doc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m["metadata"]).NotTo(HaveKey("discoveredAt"))
+			received, err := ConvertDocument(m)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*received).To(Equal(doc))
+		})
+
+		DescribeTable("fails to convert invalid documents", func(doc map[string]any, expected string) {
+			_, err := ConvertDocument(doc)
+			Expect(err).To(MatchError(expected))
+		},
+			Entry("with an unknown version", map[string]any{"apiVersion": "discovery.konveyor.io/v2"}, `unsupported discovery document version "discovery.konveyor.io/v2"`),
+			Entry("with an unknown kind", map[string]any{"apiVersion": DiscoveryAPIVersion, "kind": "Unknown"}, `unsupported discovery document kind "Unknown"`),
+			Entry("with an unversioned document without application name", map[string]any{"env": map[string]any{}},
+				`failed to convert discovery document from version "" to "discovery.konveyor.io/v1alpha1": missing application name`),
+		)
+	})
+})
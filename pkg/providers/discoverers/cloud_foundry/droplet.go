@@ -0,0 +1,164 @@
+package cloud_foundry
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
+)
+
+// getDroplet retrieves the current droplet of the live application and the package it was staged from, and downloads
+// the droplet bits when the download of the droplets is enabled. It returns nil when the application has no droplet,
+// e.g. when it was never staged. Since the droplet is not required to discover the application, a droplet or a package
+// that can't be retrieved and a droplet that can't be downloaded are reported as warnings.
+func (c *CloudFoundryProvider) getDroplet(appGUID string) (*Droplet, []pTypes.Warning) {
+	d, err := c.cli.Droplets.GetCurrentForApp(context.Background(), appGUID)
+	if isNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []pTypes.Warning{{Path: "droplet", Message: fmt.Sprintf("failed to retrieve the current droplet: %s", err)}}
+	}
+	droplet := &Droplet{
+		GUID:         d.GUID,
+		State:        string(d.State),
+		Stack:        d.Stack,
+		StartCommand: d.ProcessTypes[string(Web)],
+		ProcessTypes: d.ProcessTypes,
+		Checksum:     newChecksum(d.Checksum.Type, d.Checksum.Value),
+		CreatedAt:    formatTime(d.CreatedAt),
+	}
+	for _, b := range d.Buildpacks {
+		droplet.Buildpacks = append(droplet.Buildpacks, DetectedBuildpack{
+			Name:          b.Name,
+			BuildpackName: b.BuildpackName,
+			Version:       b.Version,
+			DetectOutput:  b.DetectOutput,
+		})
+	}
+	var warnings []pTypes.Warning
+	if link, ok := d.Links["package"]; ok && link.Href != "" {
+		droplet.Package, err = c.getPackage(path.Base(link.Href))
+		if err != nil {
+			warnings = append(warnings, pTypes.Warning{Path: "droplet.package", Message: fmt.Sprintf("failed to retrieve the package of droplet %s: %s", d.GUID, err)})
+		}
+	}
+	if c.cfg.DropletDownloadPath == "" {
+		return droplet, warnings
+	}
+	droplet.File, err = c.downloadDroplet(droplet)
+	if err != nil {
+		warnings = append(warnings, pTypes.Warning{Path: "droplet", Value: droplet.GUID, Message: err.Error()})
+	}
+	return droplet, warnings
+}
+
+// getPackage retrieves the package of a droplet. It returns nil when the package no longer exists, as the Cloud
+// Controller deletes the oldest packages of the applications.
+func (c *CloudFoundryProvider) getPackage(guid string) (*Package, error) {
+	p, err := c.cli.Packages.Get(context.Background(), guid)
+	if isNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pkg := &Package{
+		GUID:      p.GUID,
+		Type:      p.Type,
+		State:     string(p.State),
+		CreatedAt: formatTime(p.CreatedAt),
+	}
+	if p.Data.Bits != nil && p.Data.Bits.Checksum.Value != nil {
+		pkg.Checksum = newChecksum(p.Data.Bits.Checksum.Type, *p.Data.Bits.Checksum.Value)
+	}
+	return pkg, nil
+}
+
+// downloadDroplet downloads the bits of the droplet in the droplet download directory and verifies them against the
+// checksum of the droplet. It returns the path of the downloaded file, which is removed when the verification fails.
+func (c *CloudFoundryProvider) downloadDroplet(droplet *Droplet) (string, error) {
+	// The GUID comes from the Cloud Controller and names the downloaded file, so it must not escape the download
+	// directory.
+	if !isFileName(droplet.GUID) {
+		return "", fmt.Errorf("failed to download droplet %q: the GUID is not a valid file name", droplet.GUID)
+	}
+	if err := os.MkdirAll(c.cfg.DropletDownloadPath, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create the droplet download directory: %w", err)
+	}
+	bits, err := c.cli.Droplets.Download(context.Background(), droplet.GUID)
+	if err != nil {
+		return "", fmt.Errorf("failed to download droplet %s: %w", droplet.GUID, err)
+	}
+	defer bits.Close()
+
+	file := filepath.Join(c.cfg.DropletDownloadPath, droplet.GUID+".tgz")
+	f, err := os.Create(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to create the file of droplet %s: %w", droplet.GUID, err)
+	}
+	h := checksumHash(droplet.Checksum)
+	w := io.Writer(f)
+	if h != nil {
+		w = io.MultiWriter(f, h)
+	}
+	_, err = io.Copy(w, bits)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && h != nil && hex.EncodeToString(h.Sum(nil)) != droplet.Checksum.Value {
+		err = fmt.Errorf("the %s checksum does not match %s", droplet.Checksum.Type, droplet.Checksum.Value)
+	}
+	if err != nil {
+		os.Remove(file)
+		return "", fmt.Errorf("failed to download droplet %s: %w", droplet.GUID, err)
+	}
+	c.logger.Info("Downloaded the droplet", "droplet", droplet.GUID, "file", file)
+	return file, nil
+}
+
+// isFileName reports whether the name is a single path element, without separators, that does not refer to the
+// current or the parent directory.
+func isFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// checksumHash returns the hash that computes the checksum, or nil when the algorithm of the checksum is not known.
+func checksumHash(checksum *Checksum) hash.Hash {
+	if checksum == nil {
+		return nil
+	}
+	switch checksum.Type {
+	case "sha256":
+		return sha256.New()
+	case "sha1":
+		return sha1.New()
+	}
+	return nil
+}
+
+// newChecksum returns the checksum, or nil when its value is not known yet.
+func newChecksum(typ, value string) *Checksum {
+	if value == "" {
+		return nil
+	}
+	return &Checksum{Type: typ, Value: value}
+}
+
+// formatTime formats the time in RFC 3339 format, or returns an empty string for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
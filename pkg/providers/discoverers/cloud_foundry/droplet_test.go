@@ -0,0 +1,169 @@
+package cloud_foundry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Live droplet discovery", func() {
+	var (
+		created = time.Date(2025, time.May, 16, 10, 30, 0, 0, time.UTC)
+		bits    = []byte("droplet bits")
+	)
+
+	discover := func(cfg Config, app cfTypes.AppManifest, opts ...mockOption) (Application, []pTypes.Warning) {
+		discovered, result := discoverLiveApplication(GlobalT, cfg, false, app, opts...)
+		return discovered, result.Warnings
+	}
+
+	buildpackApp := cfTypes.AppManifest{Name: "app", Metadata: &cfTypes.AppMetadata{}, Buildpacks: []string{"java_buildpack_offline"}}
+
+	droplet := func(checksum string) resource.Droplet {
+		d := resource.Droplet{
+			State:        resource.DropletState(resource.DropletStateStaged),
+			Stack:        "cflinuxfs4",
+			ProcessTypes: map[string]string{"web": "JAVA_OPTS=\"-Xmx512m\" java -jar app.jar", "worker": "java -cp app.jar Worker"},
+			Buildpacks: []resource.DetectedBuildpack{
+				{Name: "java_buildpack_offline", BuildpackName: "java", Version: "4.77.0", DetectOutput: "open-jdk-like-jre=17.0.13"},
+			},
+			Resource: resource.Resource{GUID: testutil.RandomGUID(), CreatedAt: created},
+		}
+		d.Checksum.Type, d.Checksum.Value = "sha256", checksum
+		return d
+	}
+
+	bitsPackage := func() *resource.Package {
+		return &resource.Package{
+			Type:     "bits",
+			State:    resource.PackageStateReady,
+			DataRaw:  json.RawMessage(`{"checksum":{"type":"sha256","value":"0c1e3b"}}`),
+			Resource: resource.Resource{GUID: testutil.RandomGUID(), CreatedAt: created.Add(-time.Minute)},
+		}
+	}
+
+	blobstore := func() string {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(bits)
+		}))
+		DeferCleanup(s.Close)
+		return s.URL
+	}
+
+	sha := func(b []byte) string {
+		sum := sha256.Sum256(b)
+		return hex.EncodeToString(sum[:])
+	}
+
+	AfterEach(func() {
+		testutil.Teardown()
+	})
+
+	It("captures the buildpacks, the process types and the checksums of the droplet and its package", func() {
+		d, pkg := droplet("5f0a7c"), bitsPackage()
+		app, warnings := discover(Config{}, buildpackApp, withDroplet(d, pkg))
+		Expect(warnings).To(BeEmpty())
+		Expect(app.Droplet).To(Equal(&Droplet{
+			GUID:  d.GUID,
+			State: "STAGED",
+			Stack: "cflinuxfs4",
+			Buildpacks: []DetectedBuildpack{
+				{Name: "java_buildpack_offline", BuildpackName: "java", Version: "4.77.0", DetectOutput: "open-jdk-like-jre=17.0.13"},
+			},
+			StartCommand: "JAVA_OPTS=\"-Xmx512m\" java -jar app.jar",
+			ProcessTypes: map[string]string{"web": "JAVA_OPTS=\"-Xmx512m\" java -jar app.jar", "worker": "java -cp app.jar Worker"},
+			Checksum:     &Checksum{Type: "sha256", Value: "5f0a7c"},
+			CreatedAt:    "2025-05-16T10:30:00Z",
+			Package: &Package{
+				GUID:      pkg.GUID,
+				Type:      "bits",
+				State:     "READY",
+				Checksum:  &Checksum{Type: "sha256", Value: "0c1e3b"},
+				CreatedAt: "2025-05-16T10:29:00Z",
+			},
+		}))
+	})
+
+	It("leaves the package empty when it no longer exists", func() {
+		pkg := bitsPackage()
+		d := droplet("5f0a7c")
+		d.Links = resource.Links{"package": resource.Link{Href: "https://api.example.org/v3/packages/" + pkg.GUID}}
+		app, _ := discover(Config{}, buildpackApp, withDroplet(d, nil))
+		Expect(app.Droplet).NotTo(BeNil())
+		Expect(app.Droplet.Package).To(BeNil())
+	})
+
+	It("reports the droplets and the packages that can't be retrieved", func() {
+		pkg := bitsPackage()
+		app, warnings := discover(Config{}, buildpackApp, withDroplet(droplet("5f0a7c"), pkg), withForbiddenPackage())
+		Expect(app.Droplet).NotTo(BeNil())
+		Expect(app.Droplet.Package).To(BeNil())
+		Expect(warnings).To(ConsistOf(HaveField("Path", "droplet.package")))
+		testutil.Teardown()
+
+		app, warnings = discover(Config{}, buildpackApp, withDroplet(droplet("5f0a7c"), nil), withForbidden("droplets/current"))
+		Expect(app.Droplet).To(BeNil())
+		Expect(warnings).To(ConsistOf(HaveField("Path", "droplet")))
+	})
+
+	It("leaves the droplet empty when the application was never staged", func() {
+		app, _ := discover(Config{}, buildpackApp)
+		Expect(app.Droplet).To(BeNil())
+	})
+
+	It("does not capture the droplet of the docker applications", func() {
+		app, _ := discover(Config{}, cfTypes.AppManifest{Name: "app", Metadata: &cfTypes.AppMetadata{}, Docker: &cfTypes.AppManifestDocker{Image: "nginx"}})
+		Expect(app.Droplet).To(BeNil())
+	})
+
+	Context("when the download of the droplets is enabled", func() {
+		It("downloads the droplet bits and verifies their checksum", func() {
+			dir := filepath.Join(GinkgoT().TempDir(), "droplets")
+			d := droplet(sha(bits))
+			app, warnings := discover(Config{DropletDownloadPath: dir}, buildpackApp, withDroplet(d, nil), withDropletBits(blobstore()))
+			Expect(warnings).To(BeEmpty())
+			Expect(app.Droplet.File).To(Equal(filepath.Join(dir, d.GUID+".tgz")))
+			Expect(os.ReadFile(app.Droplet.File)).To(Equal(bits))
+		})
+
+		It("reports the droplets whose bits do not match their checksum", func() {
+			dir := GinkgoT().TempDir()
+			d := droplet(sha([]byte("other bits")))
+			app, warnings := discover(Config{DropletDownloadPath: dir}, buildpackApp, withDroplet(d, nil), withDropletBits(blobstore()))
+			Expect(app.Droplet.File).To(BeEmpty())
+			Expect(warnings).To(ConsistOf(And(
+				HaveField("Path", "droplet"),
+				HaveField("Message", ContainSubstring("the sha256 checksum does not match")),
+			)))
+			Expect(os.ReadDir(dir)).To(BeEmpty())
+		})
+
+		It("does not download the droplets whose GUID is not a file name", func() {
+			dir := filepath.Join(GinkgoT().TempDir(), "droplets")
+			d := droplet(sha(bits))
+			d.GUID = "../evil"
+			app, warnings := discover(Config{DropletDownloadPath: dir}, buildpackApp, withDroplet(d, nil), withDropletBits(blobstore()))
+			Expect(app.Droplet.File).To(BeEmpty())
+			Expect(warnings).To(ConsistOf(HaveField("Message", ContainSubstring("the GUID is not a valid file name"))))
+			Expect(filepath.Join(dir, "..", "evil.tgz")).NotTo(BeAnExistingFile())
+		})
+
+		It("reports the droplets that can't be downloaded", func() {
+			app, warnings := discover(Config{DropletDownloadPath: GinkgoT().TempDir()}, buildpackApp, withDroplet(droplet("5f0a7c"), nil))
+			Expect(app.Droplet).NotTo(BeNil())
+			Expect(warnings).To(ConsistOf(HaveField("Message", ContainSubstring("failed to download droplet"))))
+		})
+	})
+})
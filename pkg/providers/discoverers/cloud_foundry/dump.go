@@ -0,0 +1,269 @@
+package cloud_foundry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/cloudfoundry/go-cfclient/v3/config"
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+)
+
+const (
+	// dumpAPIEndpoint is used as API endpoint when discovering from a dump. The host is never resolved.
+	dumpAPIEndpoint = "https://api.dump.invalid"
+	// dumpRootFile is the file of the dump that contains the response of the API root, `cf curl /`. A minimal root
+	// is served when the dump does not contain it.
+	dumpRootFile = "root.json"
+)
+
+// dumpRoot is the API root served when the dump does not contain one. The service locator records point to the dump
+// itself, since the transport ignores the host of the requests.
+var dumpRoot = fmt.Sprintf(`{"links":{"self":{"href":%[1]q},"cloud_controller_v3":{"href":"%[1]s/v3"},"login":{"href":%[1]q},"uaa":{"href":%[1]q}}}`, dumpAPIEndpoint)
+
+// dumpFilters maps the query parameters of the list endpoints to the relationship of the resources they filter by.
+// The `names` and `guids` parameters filter by the name and the GUID of the resources.
+var dumpFilters = map[string]string{
+	"organization_guids": "organization",
+	"space_guids":        "space",
+	"route_guids":        "route",
+}
+
+// DumpTransport is an http.RoundTripper that serves the responses of the Cloud Controller API from a directory of
+// `cf curl` dumps, without performing any network call. The response of `GET /v3/<path>` is read from the file
+// `v3/<path>.json` of the directory, or `v3/<path>.yml` for the YAML responses such as the app manifests:
+//
+//   - The list endpoints are served from a file with every resource in a single page. The `names`, `guids`,
+//     `organization_guids`, `space_guids` and `route_guids` query parameters filter the resources, while the other
+//     query parameters, such as the pagination, are ignored.
+//   - A resource without file, e.g. `v3/apps/<guid>.json`, is served from the list of its collection, e.g.
+//     `v3/apps.json`.
+//   - The requests without file are answered with a 404 Not Found error.
+type DumpTransport struct {
+	dir string
+
+	mu    sync.Mutex
+	lists map[string][]map[string]any
+}
+
+// NewDumpTransport returns a DumpTransport that serves the dump stored in the directory.
+func NewDumpTransport(dir string) *DumpTransport {
+	return &DumpTransport{dir: dir, lists: map[string][]map[string]any{}}
+}
+
+// NewDumpConfig returns a go-cfclient configuration that serves the dump stored in the directory. Additional options
+// are applied after the dump ones.
+func NewDumpConfig(dir string, options ...config.Option) (*config.Config, error) {
+	if ok, err := isDir(dir); err != nil || !ok {
+		return nil, fmt.Errorf("the dump path %s is not a directory", dir)
+	}
+	opts := append([]config.Option{
+		config.Token("", "dump"),
+		config.HttpClient(&http.Client{Transport: NewDumpTransport(dir)}),
+	}, options...)
+	return config.New(dumpAPIEndpoint, opts...)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *DumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if strings.HasSuffix(req.URL.Path, oauthTokenPath) {
+		return replayResponse(req, RecordedResponse{
+			StatusCode:  http.StatusOK,
+			ContentType: "application/json",
+			Body:        `{"access_token":"dump","refresh_token":"dump","token_type":"bearer","expires_in":3600}`,
+		}), nil
+	}
+	if req.Method != http.MethodGet {
+		return dumpError(req, http.StatusMethodNotAllowed, resource.NewInvalidRequestError(), fmt.Sprintf("%s requests are not supported by a dump", req.Method)), nil
+	}
+	p := path.Clean("/" + req.URL.Path)
+	if p == "/" {
+		return t.serveRoot(req)
+	}
+	if b, contentType, err := t.read(p); err == nil {
+		if contentType == "application/json" && isList(b) {
+			return t.serveList(req, p, req.URL.Query())
+		}
+		return replayResponse(req, RecordedResponse{StatusCode: http.StatusOK, ContentType: contentType, Body: string(b)}), nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	// Serve the resource from the list of its collection
+	collection, guid := path.Split(p)
+	resources, err := t.list(strings.TrimSuffix(collection, "/"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, r := range resources {
+		if r["guid"] == guid {
+			b, err := json.Marshal(r)
+			if err != nil {
+				return nil, err
+			}
+			return replayResponse(req, RecordedResponse{StatusCode: http.StatusOK, ContentType: "application/json", Body: string(b)}), nil
+		}
+	}
+	return dumpError(req, http.StatusNotFound, resource.NewResourceNotFoundError(), fmt.Sprintf("%s not found in the dump", p)), nil
+}
+
+func (t *DumpTransport) serveRoot(req *http.Request) (*http.Response, error) {
+	b, err := os.ReadFile(filepath.Join(t.dir, dumpRootFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		b, err = []byte(dumpRoot), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return replayResponse(req, RecordedResponse{StatusCode: http.StatusOK, ContentType: "application/json", Body: string(b)}), nil
+}
+
+// serveList serves the resources of the list that match the filters of the query in a single page.
+func (t *DumpTransport) serveList(req *http.Request, p string, query url.Values) (*http.Response, error) {
+	resources, err := t.list(p)
+	if err != nil {
+		return nil, err
+	}
+	filtered := []map[string]any{}
+	for _, r := range resources {
+		ok, err := t.matches(r, query)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered = append(filtered, r)
+		}
+	}
+	b, err := json.Marshal(map[string]any{
+		"pagination": map[string]any{"total_results": len(filtered), "total_pages": 1},
+		"resources":  filtered,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replayResponse(req, RecordedResponse{StatusCode: http.StatusOK, ContentType: "application/json", Body: string(b)}), nil
+}
+
+// matches checks if the resource matches all the filters of the query.
+func (t *DumpTransport) matches(r map[string]any, query url.Values) (bool, error) {
+	for param, values := range query {
+		var value string
+		switch relationship, ok := dumpFilters[param]; {
+		case param == "names":
+			value, _ = r["name"].(string)
+		case param == "guids":
+			value, _ = r["guid"].(string)
+		case relationship == "organization" && relationshipGUID(r, "organization") == "":
+			// Resources that belong to a space are filtered by the organization of their space
+			org, err := t.spaceOrganization(relationshipGUID(r, "space"))
+			if err != nil {
+				return false, err
+			}
+			value = org
+		case ok:
+			value = relationshipGUID(r, relationship)
+		default:
+			continue
+		}
+		if !containsAny(values, value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// spaceOrganization returns the GUID of the organization of the space, read from the list of spaces of the dump.
+func (t *DumpTransport) spaceOrganization(spaceGUID string) (string, error) {
+	spaces, err := t.list("/v3/spaces")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	for _, s := range spaces {
+		if s["guid"] == spaceGUID {
+			return relationshipGUID(s, "organization"), nil
+		}
+	}
+	return "", nil
+}
+
+// list returns the resources of the list stored for the path.
+func (t *DumpTransport) list(p string) ([]map[string]any, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if l, ok := t.lists[p]; ok {
+		return l, nil
+	}
+	b, _, err := t.read(p)
+	if err != nil {
+		return nil, err
+	}
+	var page struct {
+		Resources []map[string]any `json:"resources"`
+	}
+	if err := json.Unmarshal(b, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode the dump of %s: %w", p, err)
+	}
+	t.lists[p] = page.Resources
+	return page.Resources, nil
+}
+
+// read returns the content of the file that stores the response for the path, and its content type.
+func (t *DumpTransport) read(p string) ([]byte, string, error) {
+	file := filepath.Join(t.dir, filepath.FromSlash(strings.TrimPrefix(p, "/")))
+	b, err := os.ReadFile(file + ".json")
+	if err == nil {
+		return b, "application/json", nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, "", err
+	}
+	b, err = os.ReadFile(file + ".yml")
+	return b, "application/x-yaml", err
+}
+
+// isList checks if the JSON document is the response of a list endpoint.
+func isList(b []byte) bool {
+	var doc map[string]json.RawMessage
+	if json.Unmarshal(b, &doc) != nil {
+		return false
+	}
+	_, ok := doc["resources"]
+	return ok
+}
+
+func relationshipGUID(r map[string]any, name string) string {
+	relationships, _ := r["relationships"].(map[string]any)
+	relationship, _ := relationships[name].(map[string]any)
+	data, _ := relationship["data"].(map[string]any)
+	guid, _ := data["guid"].(string)
+	return guid
+}
+
+// containsAny checks if the value is one of the comma separated values of the query parameter.
+func containsAny(values []string, value string) bool {
+	for _, v := range values {
+		for _, e := range strings.Split(v, ",") {
+			if e == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dumpError returns a response with the error format of the Cloud Controller API.
+func dumpError(req *http.Request, status int, cfErr resource.CloudFoundryError, detail string) *http.Response {
+	cfErr.Detail = detail
+	b, _ := json.Marshal(resource.CloudFoundryErrors{Errors: []resource.CloudFoundryError{cfErr}})
+	return replayResponse(req, RecordedResponse{StatusCode: status, ContentType: "application/json", Body: string(b)})
+}
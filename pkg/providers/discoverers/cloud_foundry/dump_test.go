@@ -0,0 +1,130 @@
+package cloud_foundry
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const dumpDir = "test_data/cf-dump"
+
+var _ = Describe("Discovery from cf curl dumps", func() {
+	var logger = logr.New(logr.Discard().GetSink())
+
+	newDumpProvider := func(cfg Config) *CloudFoundryProvider {
+		cfg.DumpPath = dumpDir
+		p, err := New(&cfg, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		return p
+	}
+
+	It("discovers the application as live discovery does", func() {
+		p := newDumpProvider(Config{})
+		result, err := p.Discover(AppReference{OrgName: "org", SpaceName: "dev", AppName: "app"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Warnings).To(BeEmpty())
+		app, err := documentSpec(result.Content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app.Name).To(Equal("app"))
+		Expect(app.Labels).To(Equal(map[string]*string{"team": ptrTo("payments")}))
+		Expect(app.Env).To(Equal(map[string]string{"JAVA_OPTS": "-Xmx512m"}))
+		Expect(app.Stack).To(Equal("cflinuxfs4"))
+		Expect(app.BuildPacks).To(Equal([]string{"java_buildpack"}))
+		By("reading the credentials of the bound services from the environment")
+		Expect(app.Services).To(HaveLen(1))
+		Expect(app.Services[0].Name).To(Equal("db"))
+		Expect(app.Services[0].BindingName).To(Equal("database"))
+		Expect(app.Services[0].Parameters).To(HaveKeyWithValue("username", "admin"))
+		Expect(app.Features).To(Equal(map[string]bool{
+			SSHFeature:                   true,
+			RevisionsFeature:             false,
+			ServiceBindingK8sFeature:     false,
+			FileBasedVCAPServicesFeature: true,
+		}))
+		Expect(app.SSH).To(Equal(&SSHSettings{Enabled: true}))
+		By("reading the package of the droplet from the link of the droplet")
+		Expect(app.Droplet).NotTo(BeNil())
+		Expect(app.Droplet.StartCommand).To(Equal("java -jar app.jar"))
+		Expect(app.Droplet.Buildpacks).To(Equal([]DetectedBuildpack{
+			{Name: "java_buildpack", BuildpackName: "java", Version: "4.77.0", DetectOutput: "open-jdk-like-jre=17.0.13"},
+		}))
+		Expect(app.Droplet.Package).To(Equal(&Package{
+			GUID:      "package-1",
+			Type:      "bits",
+			State:     "READY",
+			Checksum:  &Checksum{Type: "sha256", Value: "0c1e3b"},
+			CreatedAt: "2025-05-16T10:29:00Z",
+		}))
+		By("reading the command of the process from its own resource")
+		Expect(app.Processes).To(HaveLen(1))
+		Expect(app.Processes[0].Command).To(Equal("java -jar app.jar"))
+		Expect(app.Processes[0].Instances).To(Equal(2))
+		Expect(app.Processes[0].Memory).To(Equal("1024"))
+		Expect(app.Processes[0].HealthCheck.Endpoint).To(Equal("/health"))
+		By("capturing the route options, the route service and the destinations")
+		Expect(app.Routes.Routes).To(Equal(Routes{{
+			Route:           "app.example.com",
+			Protocol:        HTTPRouteProtocol,
+			Options:         RouteOptions{LoadBalancing: HashLoadBalancingType, HashHeader: "X-Tenant-Id", HashBalance: "1.25"},
+			RouteServiceURL: "https://waf.example.com",
+			Destinations:    []RouteDestination{{App: "app", ProcessType: Web, Port: 8080, Protocol: HTTPRouteProtocol}},
+		}}))
+	})
+
+	It("filters the applications by organization", func() {
+		p := newDumpProvider(Config{OrgNames: []string{"other"}})
+		apps, err := p.ListApps()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apps).To(HaveKey("other"))
+		Expect(apps).NotTo(HaveKey("org"))
+		Expect(apps["other"]).To(HaveLen(1))
+	})
+
+	It("discovers the application from the generated manifest of the dump", func() {
+		p := newDumpProvider(Config{Strategy: ManifestDiscoveryStrategy})
+		result, err := p.Discover(AppReference{OrgName: "org", SpaceName: "dev", AppName: "app"})
+		Expect(err).NotTo(HaveOccurred())
+		app, err := documentSpec(result.Content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app.Features).To(Equal(map[string]bool{"ssh": true}))
+	})
+
+	It("returns ErrAppNotFound when the dump does not contain the application", func() {
+		p := newDumpProvider(Config{})
+		_, err := p.Discover(AppReference{OrgName: "org", SpaceName: "dev", AppName: "missing"})
+		Expect(err).To(MatchError(ErrAppNotFound))
+	})
+
+	It("answers the requests without file with a Cloud Controller error", func() {
+		t := NewDumpTransport(dumpDir)
+		for _, r := range []struct {
+			method, target string
+			status         int
+		}{
+			{http.MethodGet, "/v3/apps/app-1", http.StatusOK},
+			{http.MethodGet, "/v3/apps/unknown", http.StatusNotFound},
+			{http.MethodGet, "/v3/apps/app-1/revisions/deployed", http.StatusNotFound},
+			{http.MethodGet, "/v3/droplets/droplet-1/download", http.StatusNotFound},
+			{http.MethodDelete, "/v3/apps/app-1", http.StatusMethodNotAllowed},
+		} {
+			resp, err := t.RoundTrip(httptest.NewRequest(r.method, dumpAPIEndpoint+r.target, nil))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(r.status), "%s %s", r.method, r.target)
+		}
+	})
+
+	It("does not change the configuration of the caller", func() {
+		cfg := Config{DumpPath: dumpDir}
+		_, err := New(&cfg, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg).To(Equal(Config{DumpPath: dumpDir}))
+	})
+
+	It("rejects a dump path that is not a directory", func() {
+		_, err := New(&Config{DumpPath: "test_data/missing"}, &logger, false)
+		Expect(err).To(MatchError(ContainSubstring("is not a directory")))
+	})
+})
@@ -0,0 +1,143 @@
+package cloud_foundry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	"golang.org/x/oauth2"
+)
+
+// Errors returned by the discovery provider. They are wrapped with the details of the failure and can be checked with
+// errors.Is.
+var (
+	// ErrAppNotFound is returned when the application to discover does not exist in the Cloud Foundry space or in the
+	// manifests.
+	ErrAppNotFound = errors.New("application not found")
+	// ErrAmbiguousApp is returned when more than one application matches the name to discover.
+	ErrAmbiguousApp = errors.New("multiple applications found")
+	// ErrAuthentication is returned when the Cloud Foundry API or UAA reject the credentials, the token or its
+	// permissions.
+	ErrAuthentication = errors.New("authentication failed")
+	// ErrInheritanceCycle is returned when a manifest inherits, directly or through its parents, from itself.
+	ErrInheritanceCycle = errors.New("manifest inheritance cycle")
+	// ErrInvalidImageCatalog is returned when an image catalog file can't be parsed or contains invalid entries.
+	ErrInvalidImageCatalog = errors.New("invalid image catalog")
+)
+
+// FieldError describes a field of the discovered application that does not satisfy a validation constraint.
+type FieldError struct {
+	// Path is the namespace of the field in the Application structure, e.g. `Application.Processes[0].Instances`.
+	Path string
+	// Field is the name of the field.
+	Field string
+	// Value is the value of the field.
+	Value any
+	// Constraint is the validation rule that failed, e.g. `required` or `max`.
+	Constraint string
+	// Param is the parameter of the validation rule, e.g. `180` for `max=180`. Empty when the rule has no parameter.
+	Param string
+	// File is the path of the manifest the field was parsed from. Empty when the application was not discovered from
+	// a manifest file.
+	File string
+	// Line and Column are the position of the field in the manifest, starting at 1. When the key of the field is not
+	// in the manifest, they are the position of the closest enclosing node (e.g. the process or the application).
+	// Zero when unknown.
+	Line   int
+	Column int
+	// Key is the path of the field in the manifest using the original key names, e.g. `processes[1].timeout`.
+	Key string
+}
+
+func (e FieldError) Error() string {
+	var msg string
+	if e.Line > 0 {
+		msg = fmt.Sprintf("%s:%d:%d: validation failed for manifest key '%s' (namespace: '%s'): actual value '%v' does not satisfy constraint '%s'",
+			e.File, e.Line, e.Column, e.Key, e.Path, e.Value, e.Constraint)
+	} else {
+		msg = fmt.Sprintf("validation failed for field '%s' (namespace: '%s'): actual value '%v' does not satisfy constraint '%s'",
+			e.Field, e.Path, e.Value, e.Constraint)
+	}
+	if e.Param != "" {
+		msg += "=" + e.Param
+	}
+	return msg
+}
+
+// ValidationError is returned when the discovered application does not satisfy the constraints of the discovery
+// manifest. It contains one entry per invalid field.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	for i, f := range e.Fields {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+		sb.WriteString(f.Error())
+	}
+	return sb.String()
+}
+
+// ManifestParseError is returned when a manifest file can not be decoded as a Cloud Foundry manifest.
+type ManifestParseError struct {
+	// File is the path of the manifest. Empty when the manifest was not read from a file.
+	File string
+	// Line is the line of the manifest where the error was found, starting at 1. Zero when unknown.
+	Line int
+	// Err is the error returned by the YAML decoder.
+	Err error
+}
+
+func (e *ManifestParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("failed to unmarshal YAML: %v", e.Err)
+	}
+	return fmt.Sprintf("failed to unmarshal YAML in %s: %v", e.File, e.Err)
+}
+
+func (e *ManifestParseError) Unwrap() error {
+	return e.Err
+}
+
+// yamlErrorLine matches the line reported by the YAML decoder errors, e.g. `yaml: line 3: mapping values are not
+// allowed in this context`.
+var yamlErrorLine = regexp.MustCompile(`line (\d+):`)
+
+// newManifestParseError wraps the YAML decoder error with the manifest file and the first line reported by the
+// decoder.
+func newManifestParseError(file string, err error) *ManifestParseError {
+	e := &ManifestParseError{File: file, Err: err}
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+	}
+	return e
+}
+
+// wrapAPIError wraps the errors returned by the Cloud Foundry client that are caused by invalid credentials or
+// tokens with ErrAuthentication.
+func wrapAPIError(err error) error {
+	if err == nil || errors.Is(err, ErrAuthentication) || !isAuthenticationError(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrAuthentication, err)
+}
+
+func isAuthenticationError(err error) bool {
+	var oauthErr *oauth2.RetrieveError
+	if errors.As(err, &oauthErr) {
+		return true
+	}
+	var httpErr resource.CloudFoundryHTTPError
+	if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden) {
+		return true
+	}
+	return resource.IsInvalidAuthTokenError(err) || resource.IsNotAuthenticatedError(err) || resource.IsNotAuthorizedError(err)
+}
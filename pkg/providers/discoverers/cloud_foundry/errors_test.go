@@ -0,0 +1,149 @@
+package cloud_foundry
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/go-cfclient/v3/config"
+	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Discovery errors", func() {
+	var logger = logr.New(logr.Discard().GetSink())
+
+	When("performing local discovery", func() {
+		It("returns a ManifestParseError with the file and line of invalid YAML", func() {
+			manifest := filepath.Join(GinkgoT().TempDir(), "manifest.yml")
+			Expect(os.WriteFile(manifest, []byte("name: app\ninstances: [1]\n"), 0644)).To(Succeed())
+			p, err := New(&Config{ManifestPath: manifest}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = p.Discover(AppReference{})
+			var parseErr *ManifestParseError
+			Expect(errors.As(err, &parseErr)).To(BeTrue())
+			Expect(parseErr.File).To(Equal(manifest))
+			Expect(parseErr.Line).To(Equal(2))
+		})
+
+		It("returns a ValidationError with the path of the invalid fields", func() {
+			manifest := filepath.Join(GinkgoT().TempDir(), "manifest.yml")
+			Expect(os.WriteFile(manifest, []byte("name: app\ntimeout: 500\n"), 0644)).To(Succeed())
+			p, err := New(&Config{ManifestPath: manifest}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = p.Discover(AppReference{})
+			var validationErr *ValidationError
+			Expect(errors.As(err, &validationErr)).To(BeTrue())
+			Expect(validationErr.Fields).To(ConsistOf(FieldError{
+				Path:       "Application.Processes[0].ProcessSpecTemplate.HealthCheck.Timeout",
+				Field:      "Timeout",
+				Value:      500,
+				Constraint: "max",
+				Param:      "180",
+				File:       manifest,
+				Line:       2,
+				Column:     10,
+				Key:        "timeout",
+			}))
+			Expect(err.Error()).To(HaveSuffix("\n" + manifest + ":2:10: validation failed for manifest key 'timeout' (namespace: 'Application.Processes[0].ProcessSpecTemplate.HealthCheck.Timeout'): actual value '500' does not satisfy constraint 'max'=180"))
+		})
+
+		It("returns ErrAppNotFound when no manifest in the directory contains the application", func() {
+			p, err := New(&Config{ManifestPath: filepath.Join("test_data", "multiple-manifests")}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = p.Discover(AppReference{AppName: "does-not-exist"})
+			Expect(err).To(MatchError(ErrAppNotFound))
+		})
+	})
+
+	When("performing live discovery", func() {
+		var (
+			g     *testutil.ObjectJSONGenerator
+			org   *testutil.JSONResource
+			space *testutil.JSONResource
+		)
+
+		BeforeEach(func() {
+			g = testutil.NewObjectJSONGenerator()
+			org = g.Organization()
+			space = g.Space()
+		})
+
+		AfterEach(func() {
+			testutil.Teardown()
+		})
+
+		discover := func(apps []string) error {
+			serverURL := testutil.SetupMultiple([]testutil.MockRoute{
+				{
+					Method:      http.MethodGet,
+					Endpoint:    "/v3/organizations",
+					Output:      g.Paged([]string{org.JSON}),
+					Status:      http.StatusOK,
+					QueryString: "names=" + org.Name + "&" + pagingQueryString,
+				},
+				{
+					Method:      http.MethodGet,
+					Endpoint:    "/v3/spaces",
+					Output:      g.Paged([]string{space.JSON}),
+					Status:      http.StatusOK,
+					QueryString: "names=" + space.Name + "&organization_guids=" + org.GUID + "&" + pagingQueryString,
+				},
+				{
+					Method:      http.MethodGet,
+					Endpoint:    "/v3/apps",
+					Output:      g.Paged(apps),
+					Status:      http.StatusOK,
+					QueryString: "names=app&organization_guids=" + org.GUID + "&" + pagingQueryString + "&space_guids=" + space.GUID,
+				},
+			}, GlobalT)
+			cfg, err := config.New(serverURL, config.Token("", "fake-refresh-token"), config.SkipTLSValidation())
+			Expect(err).NotTo(HaveOccurred())
+			p, err := New(&Config{CloudFoundryConfig: cfg}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = p.Discover(AppReference{OrgName: org.Name, SpaceName: space.Name, AppName: "app"})
+			return err
+		}
+
+		It("returns ErrAppNotFound when the application does not exist in the space", func() {
+			err := discover([]string{})
+			Expect(err).To(MatchError(ErrAppNotFound))
+			Expect(err).NotTo(MatchError(ErrAmbiguousApp))
+		})
+
+		It("returns ErrAmbiguousApp when multiple applications match the name", func() {
+			err := discover([]string{g.Application().JSON, g.Application().JSON})
+			Expect(err).To(MatchError(ErrAmbiguousApp))
+			Expect(err).NotTo(MatchError(ErrAppNotFound))
+		})
+
+		It("returns ErrAuthentication when the API rejects the token", func() {
+			notAuthorized := `{"errors":[{"code":10003,"title":"CF-NotAuthorized","detail":"You are not authorized to perform the requested action"}]}`
+			serverURL := testutil.SetupMultiple([]testutil.MockRoute{
+				{
+					Method:      http.MethodGet,
+					Endpoint:    "/v3/organizations",
+					Output:      []string{notAuthorized, notAuthorized},
+					Status:      http.StatusForbidden,
+					QueryString: "names=" + org.Name + "&" + pagingQueryString,
+				},
+			}, GlobalT)
+			cfg, err := config.New(serverURL, config.Token("", "fake-refresh-token"), config.SkipTLSValidation())
+			Expect(err).NotTo(HaveOccurred())
+			p, err := New(&Config{CloudFoundryConfig: cfg, OrgNames: []string{org.Name}}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = p.ListApps()
+			Expect(err).To(MatchError(ErrAuthentication))
+			_, err = p.Discover(AppReference{OrgName: org.Name, SpaceName: space.Name, AppName: "app"})
+			Expect(err).To(MatchError(ErrAuthentication))
+		})
+	})
+
+	It("does not wrap other API errors with ErrAuthentication", func() {
+		Expect(wrapAPIError(errors.New("connection refused"))).NotTo(MatchError(ErrAuthentication))
+		Expect(wrapAPIError(nil)).To(BeNil())
+	})
+})
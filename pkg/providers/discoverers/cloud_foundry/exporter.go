@@ -0,0 +1,165 @@
+package cloud_foundry
+
+import (
+	"fmt"
+
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportManifest generates a Cloud Foundry manifest that `cf push` accepts from the given discovered applications.
+// It is the inverse of the discovery parsing: the resulting manifest produces the same applications when discovered
+// again, which allows to back up the live state of a foundation before a migration and to compare it against the
+// manifests kept in source control.
+//
+// The space of the applications is not exported since it is not a valid attribute of a Cloud Foundry manifest.
+func ExportManifest(apps ...Application) ([]byte, error) {
+	manifests := make([]*cfTypes.AppManifest, 0, len(apps))
+	for _, app := range apps {
+		if app.Name == "" {
+			return nil, fmt.Errorf("failed to export application manifest: application name is empty")
+		}
+		m := exportAppManifest(app)
+		manifests = append(manifests, &m)
+	}
+	b, err := yaml.Marshal(cfTypes.NewCloudFoundryManifest("", manifests...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Cloud Foundry manifest: %w", err)
+	}
+	return b, nil
+}
+
+// exportAppManifest converts a discovered application into its Cloud Foundry application manifest representation.
+// The `web` process is exported using the application level attributes, as Cloud Foundry does when pushing an
+// application, and any other process is exported in the `processes` field. Applications without a `web` process are
+// discovered again with a default one, since Cloud Foundry always creates it.
+func exportAppManifest(app Application) cfTypes.AppManifest {
+	m := cfTypes.AppManifest{
+		Name:        app.Name,
+		Buildpacks:  app.BuildPacks,
+		Env:         app.Env,
+		RandomRoute: app.Routes.RandomRoute,
+		NoRoute:     app.Routes.NoRoute,
+		Routes:      exportRoutes(app.Routes.Routes),
+		Services:    exportServices(app.Services),
+		Sidecars:    exportSidecars(app.Sidecars),
+		Stack:       app.Stack,
+		Path:        app.Path,
+		Features:    app.Features,
+	}
+	if app.Docker.Image != "" {
+		m.Docker = &cfTypes.AppManifestDocker{Image: app.Docker.Image, Username: app.Docker.Username}
+	}
+	if app.Labels != nil || app.Annotations != nil {
+		m.Metadata = &cfTypes.AppMetadata{Labels: app.Labels, Annotations: app.Annotations}
+	}
+	var processes cfTypes.AppManifestProcesses
+	for _, p := range app.Processes {
+		if p.Type == Web {
+			m.AppManifestProcess = exportProcess(p)
+			m.Type = ""
+			continue
+		}
+		processes = append(processes, exportProcess(p))
+	}
+	if len(processes) > 0 {
+		m.Processes = &processes
+	}
+	// The lifecycle is an application level attribute in the manifest
+	for _, p := range app.Processes {
+		if p.Lifecycle != "" {
+			m.Lifecycle = string(p.Lifecycle)
+			break
+		}
+	}
+	return m
+}
+
+func exportRoutes(routes Routes) *cfTypes.AppManifestRoutes {
+	if len(routes) == 0 {
+		return nil
+	}
+	r := make(cfTypes.AppManifestRoutes, 0, len(routes))
+	for _, route := range routes {
+		mr := cfTypes.AppManifestRoute{
+			Route:    route.Route,
+			Protocol: cfTypes.AppRouteProtocol(route.Protocol),
+		}
+		if route.Options.LoadBalancing != "" {
+			mr.Options = &cfTypes.AppRouteOptions{
+				LoadBalancing: string(route.Options.LoadBalancing),
+				HashHeader:    route.Options.HashHeader,
+				HashBalance:   route.Options.HashBalance,
+			}
+		}
+		r = append(r, mr)
+	}
+	return &r
+}
+
+func exportServices(services Services) *cfTypes.AppManifestServices {
+	if len(services) == 0 {
+		return nil
+	}
+	s := make(cfTypes.AppManifestServices, 0, len(services))
+	for _, svc := range services {
+		s = append(s, cfTypes.AppManifestService{
+			Name:        svc.Name,
+			BindingName: svc.BindingName,
+			Parameters:  svc.Parameters,
+		})
+	}
+	return &s
+}
+
+func exportSidecars(sidecars Sidecars) *cfTypes.AppManifestSideCars {
+	if len(sidecars) == 0 {
+		return nil
+	}
+	s := make(cfTypes.AppManifestSideCars, 0, len(sidecars))
+	for _, sc := range sidecars {
+		pt := make([]cfTypes.AppProcessType, 0, len(sc.ProcessTypes))
+		for _, t := range sc.ProcessTypes {
+			pt = append(pt, cfTypes.AppProcessType(t))
+		}
+		ms := cfTypes.AppManifestSideCar{
+			Name:         sc.Name,
+			ProcessTypes: pt,
+			Command:      sc.Command,
+		}
+		if sc.Memory > 0 {
+			// The sidecar memory is captured in MB
+			ms.Memory = fmt.Sprintf("%dM", sc.Memory)
+		}
+		s = append(s, ms)
+	}
+	return &s
+}
+
+func exportProcess(proc ProcessSpec) cfTypes.AppManifestProcess {
+	mp := cfTypes.AppManifestProcess{
+		Type:                         cfTypes.AppProcessType(proc.Type),
+		Command:                      proc.Command,
+		DiskQuota:                    proc.DiskQuota,
+		Memory:                       proc.Memory,
+		LogRateLimitPerSecond:        proc.LogRateLimit,
+		HealthCheckType:              cfTypes.AppHealthCheckType(proc.HealthCheck.Type),
+		HealthCheckHTTPEndpoint:      proc.HealthCheck.Endpoint,
+		HealthCheckInvocationTimeout: uint(proc.HealthCheck.InvocationTimeout),
+		HealthCheckInterval:          uint(proc.HealthCheck.Interval),
+		Timeout:                      proc.HealthCheck.Timeout,
+	}
+	if proc.Instances > 0 {
+		instances := uint(proc.Instances)
+		mp.Instances = &instances
+	}
+	// The readiness check is forced to `process` when the health check is of type `process`, so it is only exported
+	// when it carries information.
+	if proc.HealthCheck.Type != ProcessProbeType {
+		mp.ReadinessHealthCheckType = cfTypes.AppHealthCheckType(proc.ReadinessCheck.Type)
+		mp.ReadinessHealthCheckHttpEndpoint = proc.ReadinessCheck.Endpoint
+		mp.ReadinessHealthInvocationTimeout = uint(proc.ReadinessCheck.InvocationTimeout)
+		mp.ReadinessHealthCheckInterval = uint(proc.ReadinessCheck.Interval)
+	}
+	return mp
+}
@@ -0,0 +1,114 @@
+package cloud_foundry
+
+import (
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Exporting a discovered application to a Cloud Foundry manifest", func() {
+	var logger = logr.New(logr.Discard().GetSink())
+
+	DescribeTable("round trips the exported manifest through the discovery parser", func(manifest string) {
+		p, err := New(&Config{ManifestPath: manifest}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		app, _, err := p.discoverFromManifestFile(manifest)
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := ExportManifest(*app)
+		Expect(err).NotTo(HaveOccurred())
+		var exported cfTypes.CloudFoundryManifest
+		Expect(yaml.Unmarshal(b, &exported)).To(Succeed())
+		Expect(exported.Version).To(Equal("1"))
+		Expect(exported.Applications).To(HaveLen(1))
+
+		received, err := parseCFApp(app.Space, *exported.Applications[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(Equal(*app))
+	},
+		Entry("with a basic application", filepath.Join("test_data", "basic-app", "manifest.yml")),
+		Entry("with features", filepath.Join("test_data", "app-features", "manifest.yml")),
+		Entry("with routes, services, processes and sidecars", filepath.Join("test_data", "complete-manifest", "manifest.yml")),
+		Entry("with an inline process of type web", filepath.Join("test_data", "inline-process-with-type-only-manifest", "manifest.yml")),
+		Entry("with multiple processes", filepath.Join("test_data", "multiple-processes", "manifest.yml")),
+		Entry("with an inline worker and a web process", filepath.Join("test_data", "worker-inline-and-web-processes", "manifest.yml")),
+		Entry("with a sidecar", filepath.Join("test_data", "sidecar-dependant-app", "manifest.yml")),
+		Entry("with a random route and path", filepath.Join("test_data", "hello-spring-cloud", "manifest.yml")),
+		Entry("with a docker image", filepath.Join("test_data", "process_manifest", "manifest.yml")),
+	)
+
+	It("round trips an application discovered from a live connection", func() {
+		app := Application{
+			Metadata: Metadata{
+				Name:        "live-app",
+				Space:       "space",
+				Labels:      map[string]*string{"team": ptrTo("payments")},
+				Annotations: map[string]*string{"contact": ptrTo("jane@example.com")},
+			},
+			Env:        map[string]string{"FOO": "bar"},
+			BuildPacks: []string{"java_buildpack"},
+			Stack:      "cflinuxfs4",
+			Routes: RouteSpec{Routes: Routes{
+				{Route: "live-app.example.com", Protocol: HTTP2RouteProtocol, Options: RouteOptions{LoadBalancing: LeastConnectionLoadBalancingType}},
+				{Route: "sticky.example.com", Protocol: HTTPRouteProtocol, Options: RouteOptions{LoadBalancing: HashLoadBalancingType, HashHeader: "X-Tenant-Id", HashBalance: "1.25"}},
+			}},
+			Services: Services{{Name: "db", BindingName: "database", Parameters: map[string]any{"credentials": "$(uuid)"}}},
+			Sidecars: Sidecars{{Name: "proxy", ProcessTypes: []ProcessType{Web}, Command: "./proxy", Memory: 128}},
+			Processes: Processes{
+				{Type: Web, ProcessSpecTemplate: ProcessSpecTemplate{
+					Command:      "java -jar app.jar",
+					DiskQuota:    "1024",
+					Memory:       "2048",
+					Instances:    3,
+					LogRateLimit: "-1",
+					Lifecycle:    BuildPackLifecycleType,
+					HealthCheck: HealthCheckSpec{
+						ProbeSpec: ProbeSpec{Type: HTTPProbeType, Endpoint: "/health", InvocationTimeout: 5, Interval: 10},
+						Timeout:   120,
+					},
+					ReadinessCheck: ProbeSpec{Type: PortProbeType, InvocationTimeout: 2, Interval: 15},
+				}},
+				{Type: Worker, ProcessSpecTemplate: ProcessSpecTemplate{
+					Command:      "java -cp app.jar Worker",
+					DiskQuota:    "1024",
+					Memory:       "512",
+					Instances:    1,
+					LogRateLimit: "16K",
+					Lifecycle:    BuildPackLifecycleType,
+					HealthCheck: HealthCheckSpec{
+						ProbeSpec: ProbeSpec{Type: ProcessProbeType},
+						Timeout:   60,
+					},
+					ReadinessCheck: ProbeSpec{Type: ProcessProbeType},
+				}},
+			},
+		}
+		b, err := ExportManifest(app)
+		Expect(err).NotTo(HaveOccurred())
+		var exported cfTypes.CloudFoundryManifest
+		Expect(yaml.Unmarshal(b, &exported)).To(Succeed())
+		Expect(exported.Space).To(BeEmpty())
+		received, err := parseCFApp("space", *exported.Applications[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(Equal(app))
+	})
+
+	It("exports multiple applications in the same manifest", func() {
+		b, err := ExportManifest(Application{Metadata: Metadata{Name: "app1"}}, Application{Metadata: Metadata{Name: "app2"}})
+		Expect(err).NotTo(HaveOccurred())
+		var exported cfTypes.CloudFoundryManifest
+		Expect(yaml.Unmarshal(b, &exported)).To(Succeed())
+		Expect(exported.Applications).To(HaveLen(2))
+		Expect(exported.Applications[0].Name).To(Equal("app1"))
+		Expect(exported.Applications[1].Name).To(Equal("app2"))
+	})
+
+	It("fails when the application has no name", func() {
+		_, err := ExportManifest(Application{})
+		Expect(err).To(MatchError("failed to export application manifest: application name is empty"))
+	})
+})
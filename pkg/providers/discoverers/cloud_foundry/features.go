@@ -0,0 +1,83 @@
+package cloud_foundry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+)
+
+// getFeatures retrieves the features of the live application, e.g. `ssh`, `revisions`, `service-binding-k8s` or
+// `file-based-vcap-services`, by name. It returns nil when the Cloud Controller does not support the features.
+func (c *CloudFoundryProvider) getFeatures(appGUID string) (map[string]bool, error) {
+	features, _, err := c.cli.AppFeatures.List(context.Background(), appGUID)
+	if isNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]bool{}
+	for _, f := range features {
+		m[f.Name] = f.Enabled
+	}
+	return m, nil
+}
+
+// getSSHSettings retrieves whether the runtime of the live application accepts SSH connections, which depends on
+// the `ssh` feature of the application, of its space and of the foundation. It returns nil when the Cloud Controller
+// does not report it.
+func (c *CloudFoundryProvider) getSSHSettings(appGUID string) (*SSHSettings, error) {
+	ssh, err := c.cli.Applications.SSHEnabled(context.Background(), appGUID)
+	if isNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SSHSettings{Enabled: ssh.Enabled, Reason: ssh.Reason}, nil
+}
+
+// getRevisions retrieves the deployed revisions of the live application, sorted by version. Only the applications
+// with the `revisions` feature enabled have revisions.
+func (c *CloudFoundryProvider) getRevisions(appGUID string, features map[string]bool) ([]Revision, error) {
+	if !features[RevisionsFeature] {
+		return nil, nil
+	}
+	list, err := c.cli.Revisions.ListForAppDeployedAll(context.Background(), appGUID, nil)
+	if isNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var revisions []Revision
+	for _, r := range list {
+		revisions = append(revisions, Revision{
+			Version:     r.Version,
+			Description: r.Description,
+			Droplet:     r.Droplet.GUID,
+			Deployable:  r.Deployable,
+			CreatedAt:   formatTime(r.CreatedAt),
+		})
+	}
+	slices.SortStableFunc(revisions, func(a, b Revision) int {
+		return a.Version - b.Version
+	})
+	return revisions, nil
+}
+
+// isNotFoundError checks if the error is returned by the Cloud Controller for a resource or an endpoint that does
+// not exist, e.g. an endpoint introduced in a later version of the API.
+func isNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpErr resource.CloudFoundryHTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return true
+	}
+	return resource.IsResourceNotFoundError(err) || resource.IsNotFoundError(err)
+}
@@ -0,0 +1,104 @@
+package cloud_foundry
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Live features, SSH and revisions discovery", func() {
+
+	discover := func(app cfTypes.AppManifest, opts ...mockOption) Application {
+		discovered, _ := discoverLiveApplication(GlobalT, Config{}, false, app, opts...)
+		return discovered
+	}
+
+	revision := func(version int, description string, created time.Time) resource.Revision {
+		return resource.Revision{
+			Version:     version,
+			Description: description,
+			Droplet:     resource.Relationship{GUID: "droplet-" + description},
+			Deployable:  true,
+			Resource:    resource.Resource{GUID: testutil.RandomGUID(), CreatedAt: created},
+		}
+	}
+
+	AfterEach(func() {
+		testutil.Teardown()
+	})
+
+	It("captures the features, the SSH status and the deployed revisions", func() {
+		created := time.Date(2025, time.May, 16, 10, 30, 0, 0, time.UTC)
+		app := discover(cfTypes.AppManifest{
+			Name:     "app",
+			Metadata: &cfTypes.AppMetadata{},
+			Features: map[string]bool{
+				SSHFeature:                   true,
+				RevisionsFeature:             true,
+				ServiceBindingK8sFeature:     false,
+				FileBasedVCAPServicesFeature: true,
+			},
+		},
+			withSSH(false, "Disabled for space dev"),
+			withRevisions(revision(3, "rollback", created.Add(time.Hour)), revision(2, "deploy", created)),
+		)
+		Expect(app.Features).To(Equal(map[string]bool{
+			"ssh":                      true,
+			"revisions":                true,
+			"service-binding-k8s":      false,
+			"file-based-vcap-services": true,
+		}))
+		Expect(app.SSH).To(Equal(&SSHSettings{Enabled: false, Reason: "Disabled for space dev"}))
+		By("sorting the revisions by version")
+		Expect(app.Revisions).To(Equal([]Revision{
+			{Version: 2, Description: "deploy", Droplet: "droplet-deploy", Deployable: true, CreatedAt: "2025-05-16T10:30:00Z"},
+			{Version: 3, Description: "rollback", Droplet: "droplet-rollback", Deployable: true, CreatedAt: "2025-05-16T11:30:00Z"},
+		}))
+	})
+
+	It("does not retrieve the revisions of the applications with the feature disabled", func() {
+		app := discover(cfTypes.AppManifest{
+			Name:     "app",
+			Metadata: &cfTypes.AppMetadata{},
+			Features: map[string]bool{SSHFeature: true, RevisionsFeature: false},
+		}, withRevisions(revision(1, "deploy", time.Now())))
+		Expect(app.Revisions).To(BeNil())
+	})
+
+	It("leaves the features empty when the Cloud Controller does not support them", func() {
+		app := discover(cfTypes.AppManifest{Name: "app", Metadata: &cfTypes.AppMetadata{}})
+		Expect(app.Features).To(BeNil())
+		Expect(app.SSH).To(BeNil())
+	})
+
+	It("reports the features, the SSH status and the revisions that can't be retrieved", func() {
+		app, result := discoverLiveApplication(GlobalT, Config{}, false, cfTypes.AppManifest{
+			Name:     "app",
+			Metadata: &cfTypes.AppMetadata{},
+			Features: map[string]bool{SSHFeature: true, RevisionsFeature: true},
+		},
+			withSSH(true, ""),
+			withRevisions(revision(1, "deploy", time.Now())),
+			withForbidden("ssh_enabled", "revisions/deployed"),
+		)
+		Expect(app.Features).To(HaveKeyWithValue(RevisionsFeature, true))
+		Expect(app.SSH).To(BeNil())
+		Expect(app.Revisions).To(BeNil())
+		Expect(result.Warnings).To(ConsistOf(
+			HaveField("Path", "ssh"),
+			HaveField("Path", "revisions"),
+		))
+	})
+
+	It("recognizes the errors of the endpoints that do not exist", func() {
+		Expect(isNotFoundError(resource.NewResourceNotFoundError())).To(BeTrue())
+		Expect(isNotFoundError(resource.CloudFoundryHTTPError{StatusCode: http.StatusNotFound})).To(BeTrue())
+		Expect(isNotFoundError(resource.NewNotAuthorizedError())).To(BeFalse())
+		Expect(isNotFoundError(nil)).To(BeFalse())
+	})
+})
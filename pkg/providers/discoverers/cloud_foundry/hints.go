@@ -0,0 +1,129 @@
+package cloud_foundry
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// platformRule recognizes a platform dependency by the offering or the name of a service instance, or by the name of
+// an environment variable.
+type platformRule struct {
+	dependency  PlatformDependency
+	labels      *regexp.Regexp
+	names       *regexp.Regexp
+	replacement string
+	message     string
+}
+
+// platformServices contains the Spring Cloud Services recognized in the services of the application. The instances are
+// matched by their service offering, e.g. `p.config-server` for Spring Cloud Services 3 or `p-config-server` for
+// Spring Cloud Services 2, and by name when the offering is not known, as in the manifests.
+var platformServices = []platformRule{
+	{
+		dependency:  ConfigServerDependency,
+		labels:      regexp.MustCompile(`^p[.-]config-server$`),
+		names:       regexp.MustCompile(`(?i)config[-_]?(server|service)|cloud[-_]?config`),
+		replacement: "ConfigMap and Secret with Spring Cloud Kubernetes Config",
+		message:     "store the configuration served by the Config Server in ConfigMaps and Secrets loaded with spring-cloud-starter-kubernetes-client-config, or run a Spring Cloud Config Server in the cluster",
+	},
+	{
+		dependency:  ServiceRegistryDependency,
+		labels:      regexp.MustCompile(`^p[.-]service-registry$`),
+		names:       regexp.MustCompile(`(?i)service[-_]?registry|eureka|discovery[-_]?service`),
+		replacement: "Kubernetes Service DNS",
+		message:     "expose the application with a Kubernetes Service and address the other applications by their Service DNS name, or use spring-cloud-starter-kubernetes-client for the DiscoveryClient",
+	},
+	{
+		dependency:  CircuitBreakerDashboardDependency,
+		labels:      regexp.MustCompile(`^p[.-]circuit-breaker-dashboard$`),
+		names:       regexp.MustCompile(`(?i)circuit[-_]?breaker|hystrix|turbine`),
+		replacement: "Spring Cloud Circuit Breaker with Resilience4j and Prometheus metrics",
+		message:     "the Hystrix dashboard has no Kubernetes equivalent: use Resilience4j and collect the circuit breaker metrics with Prometheus",
+	},
+}
+
+// platformEnvs contains the environment variables that configure the application for the Cloud Foundry platform.
+var platformEnvs = []platformRule{
+	{
+		dependency:  SpringProfilesDependency,
+		names:       regexp.MustCompile(`^SPRING_PROFILES_ACTIVE$`),
+		replacement: "Deployment environment variable or ConfigMap",
+		message:     "set the active profiles in the Deployment; the `cloud` profile activated by the Java buildpack is not set outside Cloud Foundry, and Spring Cloud Kubernetes activates the `kubernetes` profile instead",
+	},
+	{
+		dependency:  JavaBuildpackConfigDependency,
+		names:       regexp.MustCompile(`^JBP_CONFIG_`),
+		replacement: "Base image and JAVA_TOOL_OPTIONS",
+		message:     "the Java buildpack configuration is not applied outside Cloud Foundry: select the JRE version with the base image and set the JVM options with JAVA_TOOL_OPTIONS",
+	},
+	{
+		dependency:  ConfigServerDependency,
+		names:       regexp.MustCompile(`^SPRING_CLOUD_CONFIG_(URI|LABEL|NAME|PROFILE)$`),
+		replacement: "ConfigMap and Secret with Spring Cloud Kubernetes Config",
+		message:     "point the application to a Config Server running in the cluster or load the configuration from ConfigMaps and Secrets with spring-cloud-starter-kubernetes-client-config",
+	},
+	{
+		dependency:  ServiceRegistryDependency,
+		names:       regexp.MustCompile(`^EUREKA_`),
+		replacement: "Kubernetes Service DNS",
+		message:     "address the other applications by their Kubernetes Service DNS name instead of registering the application in Eureka",
+	},
+}
+
+// matchService reports whether the service is the platform service of the rule: by its offering when it is known, or
+// by its name otherwise.
+func (r platformRule) matchService(svc ServiceSpec) bool {
+	if svc.Label != "" {
+		return r.labels.MatchString(svc.Label)
+	}
+	return r.names.MatchString(svc.Name)
+}
+
+// DetectMigrationHints returns the Cloud Foundry platform dependencies of the application, found in its services and
+// environment variables, with the Kubernetes replacement each of them requires. The hints of the services come first,
+// in the order of the services, followed by the hints of the environment variables sorted by name.
+func DetectMigrationHints(app Application) []MigrationHint {
+	var hints []MigrationHint
+	for _, svc := range app.Services {
+		for _, ps := range platformServices {
+			if ps.matchService(svc) {
+				hints = append(hints, MigrationHint{
+					Dependency:  ps.dependency,
+					Source:      fmt.Sprintf("services[%s]", svc.Name),
+					Replacement: ps.replacement,
+					Message:     ps.message,
+				})
+				break
+			}
+		}
+	}
+	var envHints []MigrationHint
+	for name := range app.Env {
+		for _, pe := range platformEnvs {
+			if pe.names.MatchString(name) {
+				envHints = append(envHints, MigrationHint{
+					Dependency:  pe.dependency,
+					Source:      "env." + name,
+					Replacement: pe.replacement,
+					Message:     pe.message,
+				})
+				break
+			}
+		}
+	}
+	slices.SortFunc(envHints, func(a, b MigrationHint) int { return strings.Compare(a.Source, b.Source) })
+	return append(hints, envHints...)
+}
+
+// detectMigrationHints sets the migration hints of the application when the detection is enabled.
+func (c *CloudFoundryProvider) detectMigrationHints(app *Application) {
+	if !c.cfg.DetectPlatformDependencies {
+		return
+	}
+	app.MigrationHints = DetectMigrationHints(*app)
+	for _, h := range app.MigrationHints {
+		c.logger.Info("Detected a platform dependency of the application", "app_name", app.Name, "dependency", h.Dependency, "source", h.Source)
+	}
+}
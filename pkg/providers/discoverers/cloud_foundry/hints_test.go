@@ -0,0 +1,97 @@
+package cloud_foundry
+
+import (
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migration hints", func() {
+	sources := func(hints []MigrationHint) map[string]PlatformDependency {
+		m := map[string]PlatformDependency{}
+		for _, h := range hints {
+			Expect(h.Replacement).NotTo(BeEmpty())
+			Expect(h.Message).NotTo(BeEmpty())
+			m[h.Source] = h.Dependency
+		}
+		return m
+	}
+
+	It("recognizes the Spring Cloud Services bound to the application", func() {
+		hints := DetectMigrationHints(Application{Services: Services{
+			{Name: "config-server"},
+			{Name: "mysql"},
+			{Name: "my-service-registry"},
+			{Name: "circuit-breaker-dashboard"},
+			{Name: "eureka"},
+		}})
+		Expect(hints).To(HaveLen(4))
+		Expect(hints[0].Source).To(Equal("services[config-server]"))
+		Expect(hints[0].Replacement).To(ContainSubstring("ConfigMap"))
+		Expect(sources(hints)).To(Equal(map[string]PlatformDependency{
+			"services[config-server]":             ConfigServerDependency,
+			"services[my-service-registry]":       ServiceRegistryDependency,
+			"services[circuit-breaker-dashboard]": CircuitBreakerDashboardDependency,
+			"services[eureka]":                    ServiceRegistryDependency,
+		}))
+	})
+
+	It("recognizes the Spring Cloud Services by their offering before their name", func() {
+		hints := DetectMigrationHints(Application{Services: Services{
+			{Name: "settings", Label: "p.config-server"},
+			{Name: "registry", Label: "p-service-registry"},
+			{Name: "dashboard", Label: "p.circuit-breaker-dashboard"},
+			{Name: "config-server-db", Label: "p.mysql"},
+		}})
+		Expect(sources(hints)).To(Equal(map[string]PlatformDependency{
+			"services[settings]":  ConfigServerDependency,
+			"services[registry]":  ServiceRegistryDependency,
+			"services[dashboard]": CircuitBreakerDashboardDependency,
+		}))
+	})
+
+	It("recognizes the platform specific environment variables sorted by name", func() {
+		hints := DetectMigrationHints(Application{Env: map[string]string{
+			"SPRING_PROFILES_ACTIVE":                 "cloud,http2",
+			"JBP_CONFIG_OPEN_JDK_JRE":                "{ jre: { version: 17.+ } }",
+			"EUREKA_CLIENT_SERVICEURL_DEFAULTZONE":   "http://eureka/eureka",
+			"SPRING_CLOUD_CONFIG_URI":                "http://config",
+			"JAVA_OPTS":                              "-Xmx512m",
+			"JBP_CONFIG_SPRING_AUTO_RECONFIGURATION": "{enabled: false}",
+		}})
+		Expect(hints).To(HaveLen(5))
+		Expect(hints[0].Source).To(Equal("env.EUREKA_CLIENT_SERVICEURL_DEFAULTZONE"))
+		Expect(hints[0].Replacement).To(Equal("Kubernetes Service DNS"))
+		Expect(sources(hints)).To(Equal(map[string]PlatformDependency{
+			"env.EUREKA_CLIENT_SERVICEURL_DEFAULTZONE":   ServiceRegistryDependency,
+			"env.JBP_CONFIG_OPEN_JDK_JRE":                JavaBuildpackConfigDependency,
+			"env.JBP_CONFIG_SPRING_AUTO_RECONFIGURATION": JavaBuildpackConfigDependency,
+			"env.SPRING_CLOUD_CONFIG_URI":                ConfigServerDependency,
+			"env.SPRING_PROFILES_ACTIVE":                 SpringProfilesDependency,
+		}))
+	})
+
+	It("reports the migration hints in the discovery output when enabled", func() {
+		logger := logr.New(logr.Discard().GetSink())
+		manifest := filepath.Join("test_data", "spring-music", "manifest.yml")
+		p, err := New(&Config{ManifestPath: manifest, DetectPlatformDependencies: true}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		app, err := documentSpec(result.Content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sources(app.MigrationHints)).To(Equal(map[string]PlatformDependency{
+			"env.JBP_CONFIG_OPEN_JDK_JRE":                JavaBuildpackConfigDependency,
+			"env.JBP_CONFIG_SPRING_AUTO_RECONFIGURATION": JavaBuildpackConfigDependency,
+			"env.SPRING_PROFILES_ACTIVE":                 SpringProfilesDependency,
+		}))
+
+		p, err = New(&Config{ManifestPath: manifest}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		result, err = p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Content).NotTo(HaveKey("migrationHints"))
+	})
+})
@@ -0,0 +1,189 @@
+package cloud_foundry
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
+)
+
+// maxSanitizePasses limits the number of times the application is validated and adjusted, since adjusting a value can
+// reveal a new violation (e.g. an unknown required value that is dropped fails the `required` constraint next).
+const maxSanitizePasses = 3
+
+// lenientFallbacks contains the values that replace the invalid values of required fields, instead of dropping the
+// list item that contains them.
+var lenientFallbacks = map[reflect.Type]any{
+	reflect.TypeOf(ProbeType("")): ProcessProbeType,
+}
+
+// namespaceSegment matches a segment of the validator namespace with its optional index, e.g. `Processes[0]`.
+var namespaceSegment = regexp.MustCompile(`^([^\[]+)(?:\[(\d+)\])?$`)
+
+// fieldStep is a step in the path from the application to an invalid field.
+type fieldStep struct {
+	value reflect.Value
+	field reflect.StructField
+	// slice and index are set when the step is an item of a list
+	slice reflect.Value
+	index int
+	// path is the path to the step using the JSON names of the discovery content
+	path string
+}
+
+// listItemDrop is an item of a list of the application that is removed because it contains an invalid value that can
+// not be adjusted.
+type listItemDrop struct {
+	slice reflect.Value
+	index int
+	path  string
+}
+
+// sanitizeApplication adjusts the values of the application that do not satisfy the validation constraints, starting
+// from the given validation error, and returns a warning for each adjustment:
+//   - Numeric values out of range are clamped to the closest bound.
+//   - Invalid values of optional fields are dropped so that the default value applies.
+//   - Invalid values of required fields are replaced by a fallback when one exists for their type (e.g. `process` for
+//     the probe types), otherwise the list item that contains them (e.g. the route or the service) is dropped.
+//
+// The application is validated again after the adjustments and the remaining violations, if any, are returned as a
+// *ValidationError.
+func sanitizeApplication(app *Application, vErr *ValidationError) ([]pTypes.Warning, error) {
+	var warnings []pTypes.Warning
+	for range maxSanitizePasses {
+		var drops []listItemDrop
+		adjusted := false
+		for _, f := range vErr.Fields {
+			steps, err := lookupField(reflect.ValueOf(app).Elem(), f.Path)
+			if err != nil {
+				return nil, err
+			}
+			if w, ok := adjustField(steps[len(steps)-1], f); ok {
+				warnings = append(warnings, w)
+				adjusted = true
+				continue
+			}
+			// Drop the closest list item containing the field
+			for i := len(steps) - 1; i >= 0; i-- {
+				s := steps[i]
+				if !s.slice.IsValid() {
+					continue
+				}
+				d := listItemDrop{slice: s.slice, index: s.index, path: s.path}
+				if !slices.ContainsFunc(drops, func(o listItemDrop) bool { return o.path == d.path }) {
+					drops = append(drops, d)
+					// The value of the dropped item is not reported, as it can contain sensitive information such
+					// as the credentials of a service
+					warnings = append(warnings, pTypes.Warning{
+						Path:    s.path,
+						Message: fmt.Sprintf("dropped because %s does not satisfy constraint '%s'", steps[len(steps)-1].path, constraint(f)),
+					})
+				}
+				adjusted = true
+				break
+			}
+		}
+		// Remove the items from the highest index so that the indexes of the remaining items do not change
+		slices.SortFunc(drops, func(a, b listItemDrop) int { return b.index - a.index })
+		for _, d := range drops {
+			d.slice.Set(reflect.AppendSlice(d.slice.Slice(0, d.index), d.slice.Slice(d.index+1, d.slice.Len())))
+		}
+		err := validateApplication(*app)
+		if err == nil {
+			return warnings, nil
+		}
+		if !errors.As(err, &vErr) || !adjusted {
+			return warnings, err
+		}
+	}
+	return warnings, vErr
+}
+
+// adjustField clamps, replaces or drops the invalid value of the field, and returns false when the value can not be
+// adjusted without dropping the list item that contains it.
+func adjustField(s fieldStep, f FieldError) (pTypes.Warning, bool) {
+	original := s.value.Interface()
+	w := pTypes.Warning{Path: s.path, Value: original}
+	switch {
+	case (f.Constraint == "min" || f.Constraint == "max") && s.value.CanInt():
+		bound, err := strconv.ParseInt(f.Param, 10, 64)
+		if err != nil {
+			return w, false
+		}
+		s.value.SetInt(bound)
+		w.Message = fmt.Sprintf("clamped to %d to satisfy constraint '%s'", bound, constraint(f))
+		return w, true
+	case lenientFallbacks[s.value.Type()] != nil:
+		fallback := reflect.ValueOf(lenientFallbacks[s.value.Type()])
+		s.value.Set(fallback)
+		w.Message = fmt.Sprintf("replaced by '%v' to satisfy constraint '%s'", fallback.Interface(), constraint(f))
+		return w, true
+	case !isRequired(s.field):
+		s.value.Set(reflect.Zero(s.value.Type()))
+		w.Message = fmt.Sprintf("dropped to satisfy constraint '%s'", constraint(f))
+		return w, true
+	}
+	return w, false
+}
+
+// lookupField follows the validator namespace of a field, e.g. `Application.Processes[0].ProcessSpecTemplate.Instances`,
+// from the application and returns each step of the path.
+func lookupField(app reflect.Value, namespace string) ([]fieldStep, error) {
+	segments := strings.Split(namespace, ".")
+	steps := make([]fieldStep, 0, len(segments))
+	v := app
+	path := ""
+	// The first segment is the name of the application type
+	for _, segment := range segments[1:] {
+		m := namespaceSegment.FindStringSubmatch(segment)
+		if m == nil || v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("unable to find field %s", namespace)
+		}
+		sf, ok := v.Type().FieldByName(m[1])
+		if !ok {
+			return nil, fmt.Errorf("unable to find field %s", namespace)
+		}
+		v = v.FieldByIndex(sf.Index)
+		if name := jsonName(sf); name != "" {
+			path = joinPath(path, name)
+		}
+		steps = append(steps, fieldStep{value: v, field: sf, path: path})
+		if m[2] == "" {
+			continue
+		}
+		i, _ := strconv.Atoi(m[2])
+		if v.Kind() != reflect.Slice || i >= v.Len() {
+			return nil, fmt.Errorf("unable to find field %s", namespace)
+		}
+		slice := v
+		v = v.Index(i)
+		path = fmt.Sprintf("%s[%d]", path, i)
+		steps = append(steps, fieldStep{value: v, field: sf, slice: slice, index: i, path: path})
+	}
+	return steps, nil
+}
+
+// jsonName returns the name of the field in the discovery content, or empty when the field is inlined in its parent.
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" && !sf.Anonymous {
+		return sf.Name
+	}
+	return name
+}
+
+func isRequired(sf reflect.StructField) bool {
+	return slices.Contains(strings.Split(sf.Tag.Get("validate"), ","), "required")
+}
+
+func constraint(f FieldError) string {
+	if f.Param == "" {
+		return f.Constraint
+	}
+	return f.Constraint + "=" + f.Param
+}
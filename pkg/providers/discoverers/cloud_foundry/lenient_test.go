@@ -0,0 +1,125 @@
+package cloud_foundry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lenient discovery", func() {
+	var (
+		logger   = logr.New(logr.Discard().GetSink())
+		manifest string
+	)
+
+	writeManifest := func(content string) {
+		manifest = filepath.Join(GinkgoT().TempDir(), "manifest.yml")
+		Expect(os.WriteFile(manifest, []byte(content), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		writeManifest(`name: app
+timeout: 500
+health-check-type: none
+routes:
+  - route: app.example.com
+    options:
+      loadbalancing: weighted
+  - protocol: http2
+  - route: app.internal.example.com
+`)
+	})
+
+	It("fails in strict mode", func() {
+		p, err := New(&Config{ManifestPath: manifest}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = p.Discover(AppReference{})
+		var vErr *ValidationError
+		Expect(errors.As(err, &vErr)).To(BeTrue())
+		Expect(vErr.Fields).To(HaveLen(4))
+	})
+
+	It("adjusts the invalid values and reports them as warnings", func() {
+		p, err := New(&Config{ManifestPath: manifest, Lenient: true}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Warnings).To(ConsistOf(
+			pTypes.Warning{
+				Path:    "routes.routes[0]",
+				Message: "dropped because routes.routes[0].route does not satisfy constraint 'required'",
+			},
+			pTypes.Warning{
+				Path:    "routes.routes[1].options.loadBalancing",
+				Value:   LoadBalancingType("weighted"),
+				Message: "dropped to satisfy constraint 'oneof=round-robin least-connection hash'",
+			},
+			pTypes.Warning{
+				Path:    "processes[0].healthCheck.type",
+				Value:   ProbeType("none"),
+				Message: "replaced by 'process' to satisfy constraint 'oneof=http process port'",
+			},
+			pTypes.Warning{
+				Path:    "processes[0].healthCheck.timeout",
+				Value:   500,
+				Message: "clamped to 180 to satisfy constraint 'max=180'",
+			},
+		))
+
+		app, err := documentSpec(result.Content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app.Routes.Routes).To(Equal(Routes{
+			{Route: "app.example.com"},
+			{Route: "app.internal.example.com"},
+		}))
+		Expect(app.Processes).To(HaveLen(1))
+		Expect(app.Processes[0].HealthCheck.Type).To(Equal(ProcessProbeType))
+		Expect(app.Processes[0].HealthCheck.Timeout).To(Equal(180))
+		Expect(validateApplication(app)).To(Succeed())
+	})
+
+	It("does not report the sensitive information of the dropped items", func() {
+		writeManifest(`name: app
+services:
+  - name: db
+  - parameters:
+      credentials: '{"username": "admin", "password": "s3cr3t"}'
+`)
+		p, err := New(&Config{ManifestPath: manifest, Lenient: true}, &logger, true)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Warnings).To(ConsistOf(pTypes.Warning{
+			Path:    "services[0]",
+			Message: "dropped because services[0].name does not satisfy constraint 'required'",
+		}))
+		Expect(toJSON(result.Warnings)).NotTo(ContainSubstring("s3cr3t"))
+	})
+
+	It("returns no warnings for valid applications", func() {
+		p, err := New(&Config{ManifestPath: filepath.Join("test_data", "complete-manifest", "manifest.yml"), Lenient: true}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Warnings).To(BeEmpty())
+	})
+
+	It("fails when the invalid values can not be adjusted", func() {
+		writeManifest(`name: app
+docker:
+  username: user
+`)
+		p, err := New(&Config{ManifestPath: manifest, Lenient: true}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = p.Discover(AppReference{})
+		var vErr *ValidationError
+		Expect(errors.As(err, &vErr)).To(BeTrue())
+		Expect(vErr.Fields).To(HaveLen(1))
+		Expect(vErr.Fields[0].Path).To(Equal("Application.Docker.Image"))
+	})
+})
@@ -0,0 +1,268 @@
+package cloud_foundry
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// inheritKey is the legacy manifest attribute that references a parent manifest, relative to the manifest file.
+	inheritKey = "inherit"
+	// applicationsKey is the manifest attribute that contains the list of applications.
+	applicationsKey = "applications"
+	// mergeKey is the YAML merge key that inserts the keys of one or more mappings.
+	mergeKey = "<<"
+)
+
+// manifestRootKeys are the top level manifest attributes that are not global application properties.
+var manifestRootKeys = []string{inheritKey, applicationsKey, "version", "space"}
+
+// manifestDocument is a manifest file with its inheritance chain, anchors and merge keys resolved.
+type manifestDocument struct {
+	// file is the path of the manifest file.
+	file string
+	// root is the resolved mapping node of the manifest. It contains no aliases nor merge keys.
+	root *yaml.Node
+	// files maps each node of the resolved manifest to the file it was read from, which is a parent manifest when the
+	// node was inherited.
+	files map[*yaml.Node]string
+}
+
+// loadManifest reads the manifest file and resolves it as the Cloud Foundry CLI does with the legacy manifests:
+//   - The anchors are replaced by the nodes they reference and the `<<` merge keys by the keys of the merged mappings,
+//     with the keys defined explicitly taking precedence.
+//   - When the manifest contains the `inherit` attribute, the parent manifest is loaded recursively and the manifest
+//     is merged into it, overriding the parent values. An inheritance cycle returns ErrInheritanceCycle.
+//   - The top level attributes of a manifest with an `applications` list, other than `version`, `space` and
+//     `inherit`, are global properties merged into each application.
+func loadManifest(filePath string) (*manifestDocument, error) {
+	d := &manifestDocument{file: filePath, files: map[*yaml.Node]string{}}
+	root, err := d.load(filePath, nil)
+	if err != nil {
+		return nil, err
+	}
+	d.root = d.applyGlobalProperties(root)
+	return d, nil
+}
+
+func (d *manifestDocument) load(filePath string, chain []string) (*yaml.Node, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve manifest path %s: %w", filePath, err)
+	}
+	chain = append(chain, absPath)
+	if slices.Contains(chain[:len(chain)-1], absPath) {
+		return nil, fmt.Errorf("%w: %s", ErrInheritanceCycle, strings.Join(chain, " -> "))
+	}
+	data, err := readManifestFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, newManifestParseError(filePath, err)
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	if len(doc.Content) > 0 {
+		root, err = resolveMerges(doc.Content[0])
+		if err != nil {
+			return nil, newManifestParseError(filePath, err)
+		}
+	}
+	d.setFile(root, filePath)
+	parent := mappingValue(root, inheritKey)
+	if parent == nil {
+		return root, nil
+	}
+	if parent.Kind != yaml.ScalarNode || parent.Value == "" {
+		return nil, newManifestParseError(filePath, fmt.Errorf("line %d: %s must be the path of the parent manifest", parent.Line, inheritKey))
+	}
+	parentPath := parent.Value
+	if !filepath.IsAbs(parentPath) {
+		parentPath = filepath.Join(filepath.Dir(filePath), parentPath)
+	}
+	parentRoot, err := d.load(parentPath, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest %s inherited by %s: %w", parentPath, filePath, err)
+	}
+	return d.merge(parentRoot, withoutKeys(root, inheritKey)), nil
+}
+
+// applyGlobalProperties merges the global properties of the manifest into each of its applications.
+func (d *manifestDocument) applyGlobalProperties(root *yaml.Node) *yaml.Node {
+	apps := lookupKey(root, applicationsKey)
+	if apps == nil || apps.Kind != yaml.SequenceNode {
+		return root
+	}
+	globals := withoutKeys(root, manifestRootKeys...)
+	if len(globals.Content) == 0 {
+		return root
+	}
+	keys := make([]string, 0, len(globals.Content)/2)
+	for i := 0; i+1 < len(globals.Content); i += 2 {
+		keys = append(keys, globals.Content[i].Value)
+	}
+	for i, app := range apps.Content {
+		apps.Content[i] = d.merge(globals, app)
+	}
+	return withoutKeys(root, keys...)
+}
+
+// hasApplications returns true when the manifest contains a list of applications instead of a single application.
+func (d *manifestDocument) hasApplications() bool {
+	return lookupKey(d.root, applicationsKey) != nil
+}
+
+// decode decodes the resolved manifest into the value.
+func (d *manifestDocument) decode(v any) error {
+	if err := d.root.Decode(v); err != nil {
+		return newManifestParseError(d.file, err)
+	}
+	return nil
+}
+
+// applicationNode returns the node of the discovered application and the path to it, which is the first application
+// of the list when the manifest contains one.
+func (d *manifestDocument) applicationNode() (*yaml.Node, string) {
+	if !d.hasApplications() {
+		return d.root, ""
+	}
+	apps := lookupKey(d.root, applicationsKey)
+	if apps.Kind != yaml.SequenceNode || len(apps.Content) == 0 {
+		return nil, ""
+	}
+	return apps.Content[0], applicationsKey + "[0]."
+}
+
+// fileOf returns the file the node was read from.
+func (d *manifestDocument) fileOf(node *yaml.Node) string {
+	if f, ok := d.files[node]; ok {
+		return f
+	}
+	return d.file
+}
+
+func (d *manifestDocument) setFile(node *yaml.Node, file string) {
+	if _, ok := d.files[node]; ok {
+		return
+	}
+	d.files[node] = file
+	for _, n := range node.Content {
+		d.setFile(n, file)
+	}
+}
+
+// merge deeply merges the override mapping into the base mapping. The values of the keys that exist in both are
+// merged when both are mappings, otherwise the override value replaces the base value.
+func (d *manifestDocument) merge(base, override *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode {
+		return override
+	}
+	merged := copyNode(override)
+	d.files[merged] = d.fileOf(override)
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		key, value := base.Content[i], base.Content[i+1]
+		if v := mappingValue(override, key.Value); v != nil {
+			value = d.merge(value, v)
+		}
+		merged.Content = append(merged.Content, key, value)
+	}
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		if mappingValue(base, override.Content[i].Value) == nil {
+			merged.Content = append(merged.Content, override.Content[i], override.Content[i+1])
+		}
+	}
+	return merged
+}
+
+// maxResolvedNodes is the number of nodes a manifest can expand to once its aliases are resolved. It protects the
+// discovery from the manifests whose nested aliases expand exponentially, or that reference their own ancestors.
+const maxResolvedNodes = 1 << 20
+
+// resolveMerges returns a copy of the node where the aliases are replaced by the nodes they reference and the merge
+// keys by the keys of the merged mappings. The keys defined explicitly take precedence over the merged ones, and the
+// mappings listed first in a merge sequence take precedence over the following ones. It fails when the node expands
+// to more than maxResolvedNodes nodes.
+func resolveMerges(node *yaml.Node) (*yaml.Node, error) {
+	budget := maxResolvedNodes
+	return resolveMergesWithin(node, &budget)
+}
+
+// resolveMergesWithin resolves the merges of the node, decrementing the budget for each node expanded.
+func resolveMergesWithin(node *yaml.Node, budget *int) (*yaml.Node, error) {
+	if *budget--; *budget < 0 {
+		return nil, fmt.Errorf("line %d: the aliases of the manifest expand to more than %d nodes", node.Line, maxResolvedNodes)
+	}
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.SequenceNode:
+		resolved := copyNode(node)
+		for _, n := range node.Content {
+			r, err := resolveMergesWithin(n, budget)
+			if err != nil {
+				return nil, err
+			}
+			resolved.Content = append(resolved.Content, r)
+		}
+		return resolved, nil
+	case yaml.MappingNode:
+		resolved := copyNode(node)
+		var merged []*yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			value, err := resolveMergesWithin(node.Content[i+1], budget)
+			if err != nil {
+				return nil, err
+			}
+			if key.Tag != "!!merge" && key.Value != mergeKey {
+				resolved.Content = append(resolved.Content, key, value)
+				continue
+			}
+			switch value.Kind {
+			case yaml.MappingNode:
+				merged = append(merged, value)
+			case yaml.SequenceNode:
+				for _, v := range value.Content {
+					if v.Kind != yaml.MappingNode {
+						return nil, fmt.Errorf("line %d: map merge requires map or sequence of maps as the value", v.Line)
+					}
+					merged = append(merged, v)
+				}
+			default:
+				return nil, fmt.Errorf("line %d: map merge requires map or sequence of maps as the value", value.Line)
+			}
+		}
+		for _, m := range merged {
+			for i := 0; i+1 < len(m.Content); i += 2 {
+				if mappingValue(resolved, m.Content[i].Value) == nil {
+					resolved.Content = append(resolved.Content, m.Content[i], m.Content[i+1])
+				}
+			}
+		}
+		return resolved, nil
+	}
+	return node, nil
+}
+
+// withoutKeys returns a copy of the mapping node without the given keys.
+func withoutKeys(node *yaml.Node, keys ...string) *yaml.Node {
+	c := copyNode(node)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !slices.Contains(keys, node.Content[i].Value) {
+			c.Content = append(c.Content, node.Content[i], node.Content[i+1])
+		}
+	}
+	return c
+}
+
+// copyNode returns a copy of the node without its children and anchor.
+func copyNode(node *yaml.Node) *yaml.Node {
+	c := *node
+	c.Anchor = ""
+	c.Content = nil
+	return &c
+}
@@ -0,0 +1,149 @@
+package cloud_foundry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manifest inheritance and merge keys", func() {
+	var (
+		logger = logr.New(logr.Discard().GetSink())
+		dir    string
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writeManifest := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	discover := func(manifest string) (*Application, error) {
+		p, err := New(&Config{ManifestPath: manifest}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := p.Discover(AppReference{})
+		if err != nil {
+			return nil, err
+		}
+		app, err := documentSpec(result.Content)
+		return &app, err
+	}
+
+	It("merges the parent manifest and the global properties into the applications", func() {
+		app, err := discover(filepath.Join("test_data", "inherited-manifest", "manifest.yml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app.Name).To(Equal("inherited-app"))
+		Expect(app.Stack).To(Equal("cflinuxfs4"))
+		Expect(app.Env).To(Equal(map[string]string{"LOG_LEVEL": "debug", "REGION": "eu-west-1"}))
+		Expect(app.Services).To(Equal(Services{{Name: "shared-db"}}))
+		Expect(app.Processes).To(HaveLen(1))
+		Expect(app.Processes[0].Memory).To(Equal("512M"))
+		Expect(app.Processes[0].Instances).To(Equal(2))
+	})
+
+	It("lists the applications of a manifest with inherited global properties", func() {
+		p, err := New(&Config{ManifestPath: filepath.Join("test_data", "inherited-manifest", "manifest.yml")}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		apps, err := p.ListApps()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apps[defaultLocalOrg]).To(ConsistOf(AppReference{OrgName: defaultLocalOrg, SpaceName: defaultLocalSpace, AppName: "inherited-app"}))
+	})
+
+	It("resolves inheritance chains relative to each manifest", func() {
+		writeManifest("base.yml", "env:\n  FROM_BASE: \"true\"\n")
+		writeManifest("team/team.yml", "inherit: ../base.yml\nmemory: 1G\n")
+		manifest := writeManifest("team/app/manifest.yml", "inherit: ../team.yml\nname: app\n")
+		app, err := discover(manifest)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app.Name).To(Equal("app"))
+		Expect(app.Env).To(HaveKeyWithValue("FROM_BASE", "true"))
+		Expect(app.Processes[0].Memory).To(Equal("1G"))
+	})
+
+	It("fails when the inheritance chain contains a cycle", func() {
+		writeManifest("a.yml", "inherit: b.yml\nname: app\n")
+		writeManifest("b.yml", "inherit: a.yml\n")
+		_, err := discover(filepath.Join(dir, "a.yml"))
+		Expect(err).To(MatchError(ErrInheritanceCycle))
+	})
+
+	It("fails when the parent manifest does not exist", func() {
+		manifest := writeManifest("manifest.yml", "inherit: missing.yml\nname: app\n")
+		_, err := discover(manifest)
+		Expect(err).To(MatchError(ContainSubstring("failed to load manifest " + filepath.Join(dir, "missing.yml"))))
+	})
+
+	It("resolves anchors and merge keys with the explicit keys taking precedence", func() {
+		manifest := writeManifest("manifest.yml", `defaults: &defaults
+  memory: 256M
+  instances: 3
+  env: &env
+    LOG_LEVEL: info
+applications:
+  - <<: *defaults
+    name: app
+    instances: 1
+    env:
+      <<: *env
+      EXTRA: "yes"
+  - <<: *defaults
+    name: other
+`)
+		app, err := discover(manifest)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app.Name).To(Equal("app"))
+		Expect(app.Env).To(Equal(map[string]string{"LOG_LEVEL": "info", "EXTRA": "yes"}))
+		Expect(app.Processes[0].Memory).To(Equal("256M"))
+		Expect(app.Processes[0].Instances).To(Equal(1))
+	})
+
+	It("fails when the aliases of the manifest expand exponentially", func() {
+		content := "a0: &a0 [x, x, x, x, x, x, x, x, x, x]\n"
+		for i := 1; i < 10; i++ {
+			content += fmt.Sprintf("a%d: &a%d [*a%[3]d, *a%[3]d, *a%[3]d, *a%[3]d, *a%[3]d, *a%[3]d, *a%[3]d, *a%[3]d, *a%[3]d, *a%[3]d]\n", i, i, i-1)
+		}
+		manifest := writeManifest("manifest.yml", content+"name: app\n")
+		_, err := discover(manifest)
+		Expect(err).To(MatchError(ContainSubstring("the aliases of the manifest expand to more than")))
+	})
+
+	It("fails when an alias references its own ancestor", func() {
+		manifest := writeManifest("manifest.yml", "name: app\nenv: &env\n  NESTED: *env\n")
+		_, err := discover(manifest)
+		Expect(err).To(MatchError(ContainSubstring("the aliases of the manifest expand to more than")))
+	})
+
+	It("detects the single application layout when the application is defined with merge keys", func() {
+		manifest := writeManifest("manifest.yml", `base: &base
+  name: app
+  stack: cflinuxfs4
+<<: *base
+`)
+		app, err := discover(manifest)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app.Name).To(Equal("app"))
+		Expect(app.Stack).To(Equal("cflinuxfs4"))
+	})
+
+	It("reports the file of inherited invalid values", func() {
+		parent := writeManifest("base.yml", "timeout: 500\n")
+		manifest := writeManifest("manifest.yml", "inherit: base.yml\nname: app\n")
+		_, err := discover(manifest)
+		var vErr *ValidationError
+		Expect(errors.As(err, &vErr)).To(BeTrue())
+		Expect(vErr.Fields).To(HaveLen(1))
+		Expect(vErr.Fields[0].File).To(Equal(parent))
+		Expect(vErr.Fields[0].Line).To(Equal(1))
+		Expect(vErr.Fields[0].Key).To(Equal("timeout"))
+	})
+})
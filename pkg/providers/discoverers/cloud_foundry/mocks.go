@@ -6,12 +6,17 @@ import (
 	"testing"
 
 	"net/http"
+	"slices"
 	"strconv"
 
+	"github.com/cloudfoundry/go-cfclient/v3/config"
 	"github.com/cloudfoundry/go-cfclient/v3/resource"
 	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	"github.com/go-logr/logr"
 	"github.com/konveyor/asset-generation/internal/models"
+	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -29,6 +34,107 @@ type mockApplication struct {
 	resMap     map[string]any
 	g          *testutil.ObjectJSONGenerator
 	mockRoutes []testutil.MockRoute
+	// routeServices contains the URL of the route service bound to each route, by route
+	routeServices map[string]string
+	// destinations contains the destinations of each route, by route. Routes without destinations send the traffic to
+	// the web process of the application.
+	destinations map[string][]RouteDestination
+	// manifest is the manifest generated by the Cloud Controller for the application. Defaults to the manifest of the
+	// mock application.
+	manifest string
+	// unreadableApps contains the names of the other applications sharing a route whose retrieval is forbidden, as
+	// for the applications of a space the user can't read.
+	unreadableApps map[string]bool
+	// forbidden contains the endpoints of the application, relative to the application, that reject the requests
+	// as for a user without the permission to read them, e.g. `ssh_enabled`.
+	forbidden []string
+	// ssh is the SSH status of the application. The endpoint is not mocked when nil.
+	ssh *resource.AppSSHEnabled
+	// revisions contains the deployed revisions of the application.
+	revisions []resource.Revision
+	// currentDroplet is the current droplet of a buildpack application, staged from dropletPackage. The droplet of the
+	// docker applications is built from their image, and the endpoint is not mocked for the other applications.
+	currentDroplet *resource.Droplet
+	dropletPackage *resource.Package
+	// forbiddenPackage rejects the requests to the package of the droplet.
+	forbiddenPackage bool
+	// dropletBits is the blobstore URL the download of the droplet redirects to. The download is not mocked when
+	// empty.
+	dropletBits string
+}
+
+// mockOption customizes the data of a mock application that the Cloud Foundry manifest cannot represent.
+type mockOption func(*mockApplication)
+
+// withRouteService binds a route service to the route.
+func withRouteService(route, url string) mockOption {
+	return func(m *mockApplication) {
+		m.routeServices[route] = url
+	}
+}
+
+// withGeneratedManifest sets the manifest generated by the Cloud Controller for the application.
+func withGeneratedManifest(manifest string) mockOption {
+	return func(m *mockApplication) {
+		m.manifest = manifest
+	}
+}
+
+// withSSH sets the SSH status of the application.
+func withSSH(enabled bool, reason string) mockOption {
+	return func(m *mockApplication) {
+		m.ssh = &resource.AppSSHEnabled{Enabled: enabled, Reason: reason}
+	}
+}
+
+// withRevisions sets the deployed revisions of the application.
+func withRevisions(revisions ...resource.Revision) mockOption {
+	return func(m *mockApplication) {
+		m.revisions = revisions
+	}
+}
+
+// withDroplet sets the current droplet of the application and the package it was staged from, when not nil.
+func withDroplet(droplet resource.Droplet, pkg *resource.Package) mockOption {
+	return func(m *mockApplication) {
+		m.currentDroplet = &droplet
+		m.dropletPackage = pkg
+	}
+}
+
+// withForbiddenPackage rejects the requests to the package of the droplet.
+func withForbiddenPackage() mockOption {
+	return func(m *mockApplication) {
+		m.forbiddenPackage = true
+	}
+}
+
+// withDropletBits redirects the download of the current droplet to the blobstore URL.
+func withDropletBits(url string) mockOption {
+	return func(m *mockApplication) {
+		m.dropletBits = url
+	}
+}
+
+// withUnreadableApp forbids the retrieval of another application sharing a route with the application.
+func withUnreadableApp(name string) mockOption {
+	return func(m *mockApplication) {
+		m.unreadableApps[name] = true
+	}
+}
+
+// withForbidden rejects the requests to the endpoints of the application, relative to the application.
+func withForbidden(endpoints ...string) mockOption {
+	return func(m *mockApplication) {
+		m.forbidden = append(m.forbidden, endpoints...)
+	}
+}
+
+// withDestinations sets the destinations of the route.
+func withDestinations(route string, destinations ...RouteDestination) mockOption {
+	return func(m *mockApplication) {
+		m.destinations[route] = destinations
+	}
 }
 
 func (m *mockApplication) application() *testutil.JSONResource {
@@ -235,11 +341,32 @@ func (m *mockApplication) route(route models.AppManifestRoute) resource.Route {
 	}
 	testRoute.JSON = toJSON(rr)
 	// Add the destination
-	testDestination := m.destinationFor()
+	testDestination := m.destinationFor(route)
 	m.mockRoutes = append(m.mockRoutes, m.generateMockRoute("/v3/routes/"+testRoute.GUID+"/destinations", m.g.Single(testDestination.JSON), ""))
+	if route.Options != nil {
+		// The client does not decode the hash options, so the route is returned with its raw options
+		raw := map[string]any{"guid": testRoute.GUID, "url": route.Route, "options": map[string]string{
+			"loadbalancing": route.Options.LoadBalancing,
+			"hash_header":   route.Options.HashHeader,
+			"hash_balance":  route.Options.HashBalance,
+		}}
+		m.mockRoutes = append(m.mockRoutes, m.generateMockRoute("/v3/routes/"+testRoute.GUID, m.g.Single(toJSON(raw)), ""))
+	}
+	if url, ok := m.routeServices[route.Route]; ok {
+		b := resource.ServiceRouteBinding{RouteServiceURL: url, Resource: resource.Resource{GUID: testutil.RandomGUID()}}
+		b.Relationships.Route.Data = &resource.Relationship{GUID: testRoute.GUID}
+		m.resMap["routeServices"] = append(m.routeServiceBindings(), toJSON(b))
+	}
 	return rr
 }
 
+func (m *mockApplication) routeServiceBindings() []string {
+	if v, ok := m.resMap["routeServices"]; ok {
+		return v.([]string)
+	}
+	return []string{}
+}
+
 func (m *mockApplication) routes() []string {
 	if v, ok := m.resMap["routes"]; ok {
 		return v.([]string)
@@ -256,15 +383,31 @@ func (m *mockApplication) routes() []string {
 	return rList
 }
 
-func (m *mockApplication) destinationFor() testutil.JSONResource {
+func (m *mockApplication) destinationFor(route models.AppManifestRoute) testutil.JSONResource {
 	d := testutil.JSONResource{
 		GUID: testutil.RandomGUID(),
 		Name: testutil.RandomName(),
 	}
 	ds := resource.RouteDestinations{}
-	for _, v := range *m.app.Routes {
+	if dests, ok := m.destinations[route.Route]; ok {
+		for _, v := range dests {
+			guid := m.application().GUID
+			if v.App != "" && v.App != m.app.Name {
+				guid = m.foreignApplication(v.App)
+			}
+			ds.Destinations = append(ds.Destinations, &resource.RouteDestination{
+				Protocol: (*string)(&v.Protocol),
+				Port:     &v.Port,
+				Weight:   &v.Weight,
+				App: resource.RouteDestinationApp{
+					GUID:    &guid,
+					Process: &resource.RouteDestinationAppProcess{Type: string(v.ProcessType)},
+				},
+			})
+		}
+	} else {
 		ds.Destinations = append(ds.Destinations, &resource.RouteDestination{
-			Protocol: (*string)(&v.Protocol),
+			Protocol: (*string)(&route.Protocol),
 			App:      resource.RouteDestinationApp{GUID: &m.application().GUID},
 		})
 	}
@@ -272,6 +415,33 @@ func (m *mockApplication) destinationFor() testutil.JSONResource {
 	return d
 }
 
+// foreignApplication returns the GUID of another application of the foundation that shares a route with the mock
+// application, and mocks its retrieval.
+func (m *mockApplication) foreignApplication(name string) string {
+	key := "foreign/" + name
+	if guid, ok := m.resMap[key]; ok {
+		return guid.(string)
+	}
+	app := resource.App{Name: name, Resource: resource.Resource{GUID: testutil.RandomGUID()}}
+	route := m.generateMockRoute(v3apps+app.GUID, m.g.Single(toJSON(app)), "")
+	if m.unreadableApps[name] {
+		route = forbiddenRoute(v3apps + app.GUID)
+	}
+	m.mockRoutes = append(m.mockRoutes, route)
+	m.resMap[key] = app.GUID
+	return app.GUID
+}
+
+func (m *mockApplication) generatedManifest() string {
+	if m.manifest != "" {
+		return m.manifest
+	}
+	app := m.app
+	b, err := yaml.Marshal(models.CloudFoundryManifest{Version: "1", Applications: []*models.AppManifest{&app}})
+	Expect(err).NotTo(HaveOccurred())
+	return string(b)
+}
+
 func emptyResource() *testutil.JSONResource {
 	return &testutil.JSONResource{
 		GUID: testutil.RandomGUID(),
@@ -311,16 +481,49 @@ func (m *mockApplication) sidecars() []string {
 
 }
 
-func newMockApplication(app models.AppManifest, t *testing.T) (mockApplication, string) {
+func newMockApplication(app models.AppManifest, t *testing.T, opts ...mockOption) (mockApplication, string) {
 	m := mockApplication{
-		g:      testutil.NewObjectJSONGenerator(),
-		app:    app,
-		resMap: map[string]any{},
+		g:              testutil.NewObjectJSONGenerator(),
+		app:            app,
+		resMap:         map[string]any{},
+		routeServices:  map[string]string{},
+		destinations:   map[string][]RouteDestination{},
+		unreadableApps: map[string]bool{},
+	}
+	for _, o := range opts {
+		o(&m)
 	}
 	m.mockRoutes = m.setupMockRoutes()
 	return m, testutil.SetupMultiple(m.mockRoutes, t)
 }
 
+// discoverLiveApplication mocks a live foundation with the application and discovers it with the provider
+// configuration, completed with the Cloud Foundry configuration of the mock foundation. It returns the discovered
+// application along with the discovery result.
+func discoverLiveApplication(t *testing.T, cfg Config, conceal bool, app models.AppManifest, opts ...mockOption) (Application, *pTypes.DiscoverResult) {
+	m, serverURL := newMockApplication(app, t, opts...)
+	cfConfig, err := config.New(serverURL, config.Token("", "fake-refresh-token"), config.SkipTLSValidation())
+	Expect(err).NotTo(HaveOccurred())
+	cfg.CloudFoundryConfig = cfConfig
+	logger := logr.Discard()
+	p, err := New(&cfg, &logger, conceal)
+	Expect(err).NotTo(HaveOccurred())
+	result, err := p.Discover(AppReference{OrgName: m.organization().Name, SpaceName: m.space().Name, AppName: m.application().Name})
+	Expect(err).NotTo(HaveOccurred())
+	discovered, err := documentSpec(result.Content)
+	Expect(err).NotTo(HaveOccurred())
+	return discovered, result
+}
+
+// documentSpec returns the application of the discovery content, wrapped in a DiscoveryDocument or bare.
+func documentSpec(content map[string]any) (Application, error) {
+	doc, err := ConvertDocument(content)
+	if err != nil {
+		return Application{}, err
+	}
+	return doc.Spec, nil
+}
+
 const (
 	v3apps = "/v3/apps/"
 )
@@ -347,8 +550,102 @@ func (m *mockApplication) setupMockRoutes() []testutil.MockRoute {
 		m.generateMockRoute(fmt.Sprintf(v3apps+m.application().GUID+"/env"), m.g.Single(m.env().JSON), ""),
 		m.generateMockRoute(fmt.Sprintf(v3apps+m.application().GUID+"/processes"), m.g.Paged(m.processes()), pagingQueryString),
 		m.generateMockRoute(fmt.Sprintf(v3apps+m.application().GUID+"/routes"), m.g.Paged(m.routes()), ""),
+		m.generateMockRoute("/v3/service_route_bindings", m.g.Paged(m.routeServiceBindings()), ""),
+		m.generateMockRoute(fmt.Sprintf(v3apps+m.application().GUID+"/manifest"), []string{m.generatedManifest()}, ""),
 		m.generateMockRoute(fmt.Sprintf(v3apps+m.application().GUID+"/sidecars"), m.g.Paged(m.sidecars()), ""),
-		m.generateMockRoute(fmt.Sprintf(v3apps+m.application().GUID+"/droplets/current"), m.g.Single(m.droplet().JSON), ""),
 	)
-	return append(m.mockRoutes, routes...)
+	switch {
+	case m.currentDroplet != nil:
+		routes = append(routes, m.dropletRoutes()...)
+	case m.app.Docker != nil && m.app.Docker.Image != "":
+		routes = append(routes, m.generateMockRoute(v3apps+m.application().GUID+"/droplets/current", m.g.Single(m.droplet().JSON), ""))
+	}
+	// The features are only mocked when the manifest declares them, as with a Cloud Controller that does not
+	// support them
+	if m.app.Features != nil {
+		routes = append(routes, m.generateMockRoute(v3apps+m.application().GUID+"/features", m.g.Paged(m.features()), ""))
+	}
+	if m.app.Docker != nil && m.app.Docker.Image != "" {
+		routes = append(routes, m.generateMockRoute(v3apps+m.application().GUID+"/packages", m.g.Paged(m.dockerPackages()), ""))
+	}
+	if m.ssh != nil {
+		routes = append(routes, m.generateMockRoute(v3apps+m.application().GUID+"/ssh_enabled", []string{toJSON(m.ssh)}, ""))
+	}
+	if len(m.revisions) > 0 {
+		revisions := []string{}
+		for _, r := range m.revisions {
+			revisions = append(revisions, toJSON(r))
+		}
+		routes = append(routes, m.generateMockRoute(v3apps+m.application().GUID+"/revisions/deployed", m.g.Paged(revisions), pagingQueryString))
+	}
+	routes = append(m.mockRoutes, routes...)
+	for _, e := range m.forbidden {
+		endpoint := v3apps + m.application().GUID + "/" + e
+		routes = slices.DeleteFunc(routes, func(r testutil.MockRoute) bool { return r.Endpoint == endpoint })
+		routes = append(routes, forbiddenRoute(endpoint))
+	}
+	return routes
+}
+
+// forbiddenRoute returns a mock route that rejects the requests to the endpoint with the error of the Cloud Controller
+// for a user without the permission to read it.
+func forbiddenRoute(endpoint string) testutil.MockRoute {
+	return testutil.MockRoute{
+		Method:   http.MethodGet,
+		Endpoint: endpoint,
+		Output:   []string{toJSON(resource.CloudFoundryErrors{Errors: []resource.CloudFoundryError{resource.NewNotAuthorizedError()}})},
+		Status:   http.StatusForbidden,
+	}
+}
+
+// dropletRoutes returns the mock routes of the current droplet of a buildpack application, of its package and of the
+// download of its bits.
+func (m *mockApplication) dropletRoutes() []testutil.MockRoute {
+	d := *m.currentDroplet
+	if d.GUID == "" {
+		d.GUID = testutil.RandomGUID()
+	}
+	var routes []testutil.MockRoute
+	if m.dropletPackage != nil {
+		d.Links = resource.Links{"package": resource.Link{Href: "https://api.example.org/v3/packages/" + m.dropletPackage.GUID}}
+		route := m.generateMockRoute("/v3/packages/"+m.dropletPackage.GUID, []string{toJSON(m.dropletPackage)}, "")
+		if m.forbiddenPackage {
+			route = forbiddenRoute(route.Endpoint)
+		}
+		routes = append(routes, route)
+	}
+	routes = append(routes, m.generateMockRoute(v3apps+m.application().GUID+"/droplets/current", []string{toJSON(d)}, ""))
+	if m.dropletBits != "" {
+		routes = append(routes, testutil.MockRoute{
+			Method:           http.MethodGet,
+			Endpoint:         "/v3/droplets/" + d.GUID + "/download",
+			Status:           http.StatusFound,
+			RedirectLocation: m.dropletBits,
+		})
+	}
+	return routes
+}
+
+// dockerPackages returns the docker package of the application, with the registry credentials of the manifest. The
+// password is masked as the Cloud Controller does.
+func (m *mockApplication) dockerPackages() []string {
+	data := resource.DockerPackage{Image: m.app.Docker.Image}
+	if m.app.Docker.Username != "" {
+		data.DockerCredentials = &resource.DockerCredentials{Username: m.app.Docker.Username, Password: "***"}
+	}
+	p := map[string]any{
+		"guid":  testutil.RandomGUID(),
+		"type":  "docker",
+		"state": resource.PackageStateReady,
+		"data":  data,
+	}
+	return []string{toJSON(p)}
+}
+
+func (m *mockApplication) features() []string {
+	features := []string{}
+	for name, enabled := range m.app.Features {
+		features = append(features, toJSON(resource.AppFeature{Name: name, Enabled: enabled}))
+	}
+	return features
 }
@@ -1,10 +1,12 @@
 package cloud_foundry
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 
 	cfTypes "github.com/konveyor/asset-generation/internal/models"
@@ -121,6 +123,8 @@ func parseRoutes(cfRoutes cfTypes.AppManifestRoutes) Routes {
 		options := RouteOptions{}
 		if cfRoute.Options != nil {
 			options.LoadBalancing = LoadBalancingType(cfRoute.Options.LoadBalancing)
+			options.HashHeader = cfRoute.Options.HashHeader
+			options.HashBalance = cfRoute.Options.HashBalance
 		}
 		r := Route{
 			Route:    cfRoute.Route,
@@ -237,7 +241,7 @@ func parseSidecar(sidecar cfTypes.AppManifestSideCar) (*SidecarSpec, error) {
 	if len(sidecar.Memory) > 0 {
 		mem, err = parseSidecarMemory(sidecar.Memory)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse memory value for sidecar %s: %s", sidecar.Name, err)
+			return nil, fmt.Errorf("failed to parse memory value for sidecar %s: %w", sidecar.Name, err)
 		}
 	}
 	s := SidecarSpec{
@@ -319,6 +323,7 @@ var parseCFApp = func(spaceName string, cfApp cfTypes.AppManifest) (Application,
 	if err != nil {
 		return Application{}, err
 	}
+	completeDockerRegistry(&docker)
 	var sidecars Sidecars
 	if cfApp.Sidecars != nil {
 		sidecars, err = parseSidecars(*cfApp.Sidecars)
@@ -332,6 +337,12 @@ var parseCFApp = func(spaceName string, cfApp cfTypes.AppManifest) (Application,
 		if err != nil {
 			return Application{}, err
 		}
+		// The lifecycle is an application level attribute that applies to all its processes
+		for i := range processes {
+			if processes[i].Lifecycle == "" {
+				processes[i].Lifecycle = LifecycleType(cfApp.Lifecycle)
+			}
+		}
 	}
 	var labels, annotations map[string]*string
 
@@ -378,33 +389,61 @@ var parseCFApp = func(spaceName string, cfApp cfTypes.AppManifest) (Application,
 			app.Processes = append(app.Processes, *inlineProcess)
 		}
 	}
-	validationErrors := validateApplication(app)
-	if validationErrors != nil {
-		return Application{}, validationErrors
+	sortApplication(&app)
+	// The application is returned with the validation errors so that the invalid values can be adjusted in lenient mode
+	return app, validateApplication(app)
+}
+
+// sortApplication sorts the lists of the application in their canonical order, so that the discovery output does not
+// depend on the order of the manifest fields, the Cloud Foundry API responses or the VCAP_SERVICES entries: services
+// and sidecars by name, processes with `web` first and then by type, and routes by URL. The order of the buildpacks
+// is kept since it determines the order in which they are applied.
+func sortApplication(app *Application) {
+	slices.SortStableFunc(app.Services, func(a, b ServiceSpec) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.BindingName, b.BindingName))
+	})
+	slices.SortStableFunc(app.Processes, func(a, b ProcessSpec) int { return compareProcessTypes(a.Type, b.Type) })
+	slices.SortStableFunc(app.Routes.Routes, func(a, b Route) int { return cmp.Compare(a.Route, b.Route) })
+	slices.SortStableFunc(app.Sidecars, func(a, b SidecarSpec) int { return cmp.Compare(a.Name, b.Name) })
+	for i := range app.Sidecars {
+		slices.SortStableFunc(app.Sidecars[i].ProcessTypes, compareProcessTypes)
+	}
+}
+
+// compareProcessTypes orders the `web` process type before any other type, and the rest alphabetically.
+func compareProcessTypes(a, b ProcessType) int {
+	switch {
+	case a == b:
+		return 0
+	case a == Web:
+		return -1
+	case b == Web:
+		return 1
 	}
-	return app, nil
+	return cmp.Compare(a, b)
 }
 
+// validateApplication validates the application against the constraints of the discovery manifest and returns a
+// *ValidationError with the fields that do not satisfy them.
 func validateApplication(app Application) error {
 	validate := validator.New(validator.WithRequiredStructEnabled())
 	err := validate.Struct(app)
 	if err != nil {
-		var errorList error
-		for _, err := range err.(validator.ValidationErrors) {
-			detailedMsg := fmt.Sprintf(
-				"\nvalidation failed for field '%s' (namespace: '%s'): actual value '%v' does not satisfy constraint '%s'",
-				err.Field(),
-				err.Namespace(),
-				err.Value(),
-				err.Tag(),
-			)
-			// Include parameter if available (e.g., max=10)
-			if param := err.Param(); param != "" {
-				detailedMsg += fmt.Sprintf("=%s", param)
-			}
-			errorList = errors.Join(errorList, errors.New(detailedMsg))
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return err
+		}
+		vErr := &ValidationError{}
+		for _, err := range validationErrors {
+			vErr.Fields = append(vErr.Fields, FieldError{
+				Path:       err.Namespace(),
+				Field:      err.Field(),
+				Value:      err.Value(),
+				Constraint: err.Tag(),
+				Param:      err.Param(),
+			})
 		}
-		return errorList
+		return vErr
 	}
 	return nil
 }
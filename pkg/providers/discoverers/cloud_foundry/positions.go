@@ -0,0 +1,198 @@
+package cloud_foundry
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifestKeys maps the namespace of the Application fields, relative to the application or to the list item that
+// contains them, to the key of the Cloud Foundry manifest they are parsed from.
+var manifestKeys = map[string]string{
+	"Metadata.Name":        "name",
+	"Metadata.Labels":      "metadata.labels",
+	"Metadata.Annotations": "metadata.annotations",
+	"Env":                  "env",
+	"BuildPacks":           "buildpacks",
+	"Stack":                "stack",
+	"Path":                 "path",
+	"Features":             "features",
+	"Docker":               "docker",
+	"Docker.Image":         "docker.image",
+	"Docker.Username":      "docker.username",
+	"Routes.NoRoute":       "no-route",
+	"Routes.RandomRoute":   "random-route",
+	// Routes
+	"Route":                 "route",
+	"Protocol":              "protocol",
+	"Options.LoadBalancing": "options.loadbalancing",
+	"Options.HashHeader":    "options.hash_header",
+	"Options.HashBalance":   "options.hash_balance",
+	// Services and sidecars
+	"Name":         "name",
+	"BindingName":  "binding_name",
+	"Parameters":   "parameters",
+	"ProcessTypes": "process_types",
+	"Command":      "command",
+	"Memory":       "memory",
+	// Processes
+	"Type":                                                        "type",
+	"ProcessSpecTemplate.Command":                                 "command",
+	"ProcessSpecTemplate.DiskQuota":                               "disk_quota",
+	"ProcessSpecTemplate.Memory":                                  "memory",
+	"ProcessSpecTemplate.Instances":                               "instances",
+	"ProcessSpecTemplate.LogRateLimit":                            "log-rate-limit-per-second",
+	"ProcessSpecTemplate.Lifecycle":                               "lifecycle",
+	"ProcessSpecTemplate.HealthCheck.Timeout":                     "timeout",
+	"ProcessSpecTemplate.HealthCheck.ProbeSpec.Type":              "health-check-type",
+	"ProcessSpecTemplate.HealthCheck.ProbeSpec.Endpoint":          "health-check-http-endpoint",
+	"ProcessSpecTemplate.HealthCheck.ProbeSpec.Interval":          "health-check-interval",
+	"ProcessSpecTemplate.HealthCheck.ProbeSpec.InvocationTimeout": "health-check-invocation-timeout",
+	"ProcessSpecTemplate.ReadinessCheck.Type":                     "readiness-health-check-type",
+	"ProcessSpecTemplate.ReadinessCheck.Endpoint":                 "readiness-health-check-http-endpoint",
+	"ProcessSpecTemplate.ReadinessCheck.Interval":                 "readiness-health-check-interval",
+	"ProcessSpecTemplate.ReadinessCheck.InvocationTimeout":        "readiness-health-invocation-timeout",
+}
+
+// manifestList describes a list of the application that is parsed from a list of the manifest, whose items are
+// matched by the value of their identity key since the discovered lists are sorted.
+type manifestList struct {
+	key      string
+	identity string
+	value    func(app Application, i int) string
+}
+
+var manifestLists = map[string]manifestList{
+	"Routes.Routes": {key: "routes", identity: "route", value: func(app Application, i int) string { return app.Routes.Routes[i].Route }},
+	"Services":      {key: "services", identity: "name", value: func(app Application, i int) string { return app.Services[i].Name }},
+	"Sidecars":      {key: "sidecars", identity: "name", value: func(app Application, i int) string { return app.Sidecars[i].Name }},
+	"Processes":     {key: "processes", identity: "type", value: func(app Application, i int) string { return string(app.Processes[i].Type) }},
+}
+
+// listItemNamespace matches the namespace of a field inside a list item of the application, e.g.
+// `Processes[0].ProcessSpecTemplate.Instances`.
+var listItemNamespace = regexp.MustCompile(`^(Routes\.Routes|Services|Sidecars|Processes)\[(\d+)\](?:\.(.+))?$`)
+
+// locateFieldErrors sets the position in the manifest file and the manifest key of each invalid field of the
+// validation error. The app node is the YAML node of the application in the manifest, the key prefix is the path to it,
+// e.g. `applications[0].` for a Cloud Foundry manifest with a list of applications, and fileOf returns the manifest
+// file of a node.
+func locateFieldErrors(vErr *ValidationError, app Application, appNode *yaml.Node, keyPrefix string, fileOf func(*yaml.Node) string) {
+	for i := range vErr.Fields {
+		f := &vErr.Fields[i]
+		container, containerKey, key := locateContainer(strings.TrimPrefix(f.Path, "Application."), app, appNode)
+		node := container
+		if key != "" {
+			node = closestNode(container, key)
+			if node == container && containerKey != "" && key == "lifecycle" {
+				// The processes inherit the lifecycle of the application
+				if n := lookupKey(appNode, key); n != nil {
+					node, containerKey = n, ""
+				}
+			}
+		}
+		f.File = fileOf(node)
+		f.Line, f.Column = node.Line, node.Column
+		f.Key = keyPrefix + joinPath(containerKey, key)
+	}
+}
+
+// locateContainer returns the YAML node containing the field with the given namespace, relative to the application,
+// its path in the manifest and the manifest key of the field in it.
+func locateContainer(namespace string, app Application, appNode *yaml.Node) (*yaml.Node, string, string) {
+	m := listItemNamespace.FindStringSubmatch(namespace)
+	if m == nil {
+		return appNode, "", manifestKeys[namespace]
+	}
+	list := manifestLists[m[1]]
+	index, _ := strconv.Atoi(m[2])
+	key := manifestKeys[m[3]]
+	items := lookupKey(appNode, list.key)
+	if items == nil || items.Kind != yaml.SequenceNode {
+		// The web process can be defined at the application level
+		return appNode, "", key
+	}
+	identity := list.value(app, index)
+	for i, item := range items.Content {
+		if itemIdentity(resolveAlias(item), list.identity) == identity {
+			return resolveAlias(item), fmt.Sprintf("%s[%d]", list.key, i), key
+		}
+	}
+	if m[1] == "Processes" {
+		return appNode, "", key
+	}
+	return items, list.key, ""
+}
+
+// itemIdentity returns the value of the identity key of a list item, or the item itself when it is a scalar, e.g. a
+// service listed by name.
+func itemIdentity(item *yaml.Node, identity string) string {
+	if item.Kind == yaml.ScalarNode {
+		return item.Value
+	}
+	if n := lookupKey(item, identity); n != nil && n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	return ""
+}
+
+// closestNode returns the value node of a dotted key path in a mapping node, or the value of its longest existing
+// prefix when the key does not exist, e.g. the `docker` node for a missing `docker.image`.
+func closestNode(node *yaml.Node, key string) *yaml.Node {
+	for k := key; k != ""; {
+		if n := lookupKey(node, k); n != nil {
+			return n
+		}
+		i := strings.LastIndex(k, ".")
+		if i < 0 {
+			break
+		}
+		k = k[:i]
+	}
+	return node
+}
+
+// lookupKey returns the value node of a dotted key path in a mapping node, or nil when the key does not exist.
+func lookupKey(node *yaml.Node, key string) *yaml.Node {
+	for _, k := range strings.Split(key, ".") {
+		node = resolveAlias(node)
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		node = mappingValue(node, k)
+	}
+	return resolveAlias(node)
+}
+
+// mappingValue returns the value node of the key in a mapping node, or nil when the key does not exist.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// locateValidationError sets the manifest positions of the invalid fields when the error is a *ValidationError of the
+// application parsed from the manifest.
+func locateValidationError(err error, app Application, doc *manifestDocument) {
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		return
+	}
+	if appNode, keyPrefix := doc.applicationNode(); appNode != nil {
+		locateFieldErrors(vErr, app, appNode, keyPrefix, doc.fileOf)
+	}
+}
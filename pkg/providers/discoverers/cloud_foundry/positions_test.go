@@ -0,0 +1,69 @@
+package cloud_foundry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manifest positions of validation errors", func() {
+	var logger = logr.New(logr.Discard().GetSink())
+
+	type position struct {
+		Key    string
+		Line   int
+		Column int
+	}
+
+	DescribeTable("reports the file, position and manifest key of the invalid fields", func(content string, expected ...position) {
+		manifest := filepath.Join(GinkgoT().TempDir(), "manifest.yml")
+		Expect(os.WriteFile(manifest, []byte(content), 0644)).To(Succeed())
+		p, err := New(&Config{ManifestPath: manifest}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = p.Discover(AppReference{})
+		var vErr *ValidationError
+		Expect(errors.As(err, &vErr)).To(BeTrue())
+		received := make([]position, 0, len(vErr.Fields))
+		for _, f := range vErr.Fields {
+			Expect(f.File).To(Equal(manifest))
+			received = append(received, position{Key: f.Key, Line: f.Line, Column: f.Column})
+		}
+		Expect(received).To(ConsistOf(expected))
+	},
+		Entry("with a field of the application level process", `name: app
+instances: 1
+health-check-type: none
+`, position{Key: "health-check-type", Line: 3, Column: 20}),
+		Entry("with a field of a process matched by its type", `name: app
+processes:
+  - type: worker
+    timeout: 500
+  - type: web
+    readiness-health-check-type: none
+`,
+			position{Key: "processes[0].timeout", Line: 4, Column: 14},
+			position{Key: "processes[1].readiness-health-check-type", Line: 6, Column: 34}),
+		Entry("with routes matched by their URL", `name: app
+routes:
+  - route: b.example.com
+    options:
+      loadbalancing: weighted
+  - protocol: http2
+`,
+			position{Key: "routes[0].options.loadbalancing", Line: 5, Column: 22},
+			position{Key: "routes[1].route", Line: 6, Column: 5}),
+		Entry("with a missing key reported at the enclosing node", `name: app
+docker:
+  username: user
+`, position{Key: "docker.image", Line: 3, Column: 3}),
+		Entry("with an application of a Cloud Foundry manifest", `version: 1
+applications:
+  - name: app
+    lifecycle: unknown
+`, position{Key: "applications[0].lifecycle", Line: 4, Column: 16}),
+	)
+})
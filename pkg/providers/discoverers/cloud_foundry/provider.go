@@ -1,14 +1,19 @@
 package cloud_foundry
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cloudfoundry/go-cfclient/v3/client"
 	"github.com/cloudfoundry/go-cfclient/v3/config"
@@ -18,7 +23,6 @@ import (
 	"github.com/go-logr/logr"
 	cfTypes "github.com/konveyor/asset-generation/internal/models"
 	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
-	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -33,6 +37,52 @@ type Config struct {
 	CloudFoundryConfig *config.Config `json:"cloud_foundry_config,omitempty" yaml:"cloud_foundry_config,omitempty"`
 	SpaceNames         []string       `json:"space_names" yaml:"space_names"`
 	OrgNames           []string       `json:"org_names" yaml:"org_names"`
+	// Auth declares the credentials used to discover a live foundation, from the CF CLI configuration, a password
+	// grant or a client credentials grant. It is ignored when CloudFoundryConfig is set.
+	Auth *AuthConfig `json:"auth,omitempty" yaml:"auth,omitempty"`
+	// DumpPath is the path of a directory of `cf curl` dumps of the Cloud Controller API to discover the applications
+	// offline, as from a live foundation. It is ignored when CloudFoundryConfig or Auth are set.
+	DumpPath string `json:"dump_path,omitempty" yaml:"dump_path,omitempty"`
+	// BareOutput returns the discovered application as the content of the discovery result, as previous versions
+	// did, instead of wrapping it in a DiscoveryDocument envelope with its version and provenance. It is meant for
+	// the consumers that read the application fields at the top level of the content, e.g. the helm charts that
+	// read `.Values.name` instead of `.Values.spec.name`, until they are migrated. ConvertDocument upgrades the bare
+	// content.
+	BareOutput bool `json:"bare_output,omitempty" yaml:"bare_output,omitempty"`
+	// Deprecated: the discovered application is wrapped in a DiscoveryDocument envelope by default. Set BareOutput
+	// to return the bare application instead.
+	VersionedOutput bool `json:"versioned_output,omitempty" yaml:"versioned_output,omitempty"`
+	// Lenient adjusts or drops the values of the discovered application that do not satisfy the validation
+	// constraints instead of failing the discovery, and reports each adjustment as a warning in the discovery
+	// result. When disabled, any invalid value fails the discovery.
+	Lenient bool `json:"lenient,omitempty" yaml:"lenient,omitempty"`
+	// InspectSource inspects the application source at the `path` of the manifest, relative to the manifest file,
+	// to detect its language runtime, framework and Procfile commands during local discovery.
+	InspectSource bool `json:"inspect_source,omitempty" yaml:"inspect_source,omitempty"`
+	// ResolveImages resolves the container images and Cloud Native Buildpacks recommended for the discovered
+	// application from its buildpacks and stack with the default image catalog.
+	ResolveImages bool `json:"resolve_images,omitempty" yaml:"resolve_images,omitempty"`
+	// ImageCatalogPath is the path of an image catalog file that overrides the entries of the default image catalog.
+	// Setting it enables the image resolution.
+	ImageCatalogPath string `json:"image_catalog_path,omitempty" yaml:"image_catalog_path,omitempty"`
+	// DetectPlatformDependencies reports the Spring Cloud Services and the platform specific environment variables
+	// used by the discovered application as migration hints.
+	DetectPlatformDependencies bool `json:"detect_platform_dependencies,omitempty" yaml:"detect_platform_dependencies,omitempty"`
+	// Strategy selects how live discovery retrieves the applications: from their resources, from the manifest
+	// generated by the Cloud Controller, or from both while reporting where they disagree. Defaults to the resources.
+	Strategy DiscoveryStrategy `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	// MapRoutes lists the routes of each space with their destinations while listing the applications of a live
+	// foundation, to detect the routes shared by several applications, e.g. blue/green deployments. The shared
+	// routes are returned by SharedRoutes.
+	MapRoutes bool `json:"map_routes,omitempty" yaml:"map_routes,omitempty"`
+	// AutoscalerURL is the URL of the App Autoscaler API used to retrieve the scaling policies of the applications
+	// bound to the App Autoscaler during live discovery. Defaults to the Cloud Foundry API URL with its `api` host
+	// label replaced by `autoscale`.
+	AutoscalerURL string `json:"autoscaler_url,omitempty" yaml:"autoscaler_url,omitempty"`
+	// DropletDownloadPath is the path of a directory where live discovery downloads the bits of the current droplet
+	// of the applications, in `<droplet guid>.tgz`, to rebuild the images from what runs in the foundation. The
+	// droplets are not downloaded when empty.
+	DropletDownloadPath string `json:"droplet_download_path,omitempty" yaml:"droplet_download_path,omitempty"`
 	// Cloud Foundry transient client
 	Client *client.Client `json:"-" yaml:"-"`
 }
@@ -41,6 +91,16 @@ type CloudFoundryProvider struct {
 	cfg    *Config
 	logger *logr.Logger
 	cli    *client.Client
+	// cfConfig is the configuration of the Cloud Foundry client: the CloudFoundryConfig of the configuration, or the
+	// one derived from its Auth or DumpPath. The configuration of the caller is left unchanged.
+	cfConfig *config.Config
+	// catalog resolves the container images of the discovered applications. Nil when image resolution is disabled.
+	catalog *ImageCatalog
+	// appNames caches the names of the live applications by GUID, to name the destinations of the routes.
+	appNames map[string]string
+	// routeMap contains the destinations of the routes listed with the applications, by route URL. Only populated
+	// when MapRoutes is enabled.
+	routeMap map[string][]RouteDestination
 	// conceal extracts the sensitive information found in the CF manifest into a separate file and uses a
 	// unique ID to link each of the items found between the discover manifest and this new file containing the
 	// sensitive information
@@ -55,18 +115,44 @@ type ClientProvider interface {
 // New creates a new CloudFoundryProvider instance with the given configuration.
 // If CloudFoundryConfig is provided, it initializes the Cloud Foundry client for live discovery.
 func New(cfg *Config, logger *logr.Logger, conceal bool) (*CloudFoundryProvider, error) {
+	if err := validateStrategy(cfg.Strategy); err != nil {
+		return nil, err
+	}
 	var err error
 	cp := CloudFoundryProvider{
-		cfg:     cfg,
-		logger:  logger,
-		conceal: conceal,
+		cfg:      cfg,
+		logger:   logger,
+		conceal:  conceal,
+		appNames: map[string]string{},
+		routeMap: map[string][]RouteDestination{},
+	}
+	switch {
+	case cfg.ImageCatalogPath != "":
+		cp.catalog, err = LoadImageCatalog(cfg.ImageCatalogPath)
+	case cfg.ResolveImages:
+		cp.catalog, err = DefaultImageCatalog()
 	}
-	if cfg.CloudFoundryConfig != nil {
-		cp.cli, err = cp.getClient()
+	if err != nil {
+		return nil, err
+	}
+	cp.cfConfig = cfg.CloudFoundryConfig
+	if cp.cfConfig == nil && cfg.ManifestPath == "" {
+		switch {
+		case cfg.Auth != nil:
+			cp.cfConfig, err = NewConfigFromAuth(*cfg.Auth)
+		case cfg.DumpPath != "":
+			cp.cfConfig, err = NewDumpConfig(cfg.DumpPath)
+		}
 		if err != nil {
 			return nil, err
 		}
 	}
+	if cp.cfConfig != nil {
+		cp.cli, err = cp.getClient()
+		if err != nil {
+			return nil, wrapAPIError(err)
+		}
+	}
 	return &cp, nil
 }
 
@@ -77,12 +163,11 @@ func (c *CloudFoundryProvider) getClient() (*client.Client, error) {
 		return c.cfg.Client, nil
 	}
 
-	cf, err := client.New(c.cfg.CloudFoundryConfig)
+	cf, err := client.New(c.cfConfig)
 	if err != nil {
 		return nil, err
 	}
 	c.logger.Info("Cloud Foundry client created successfully")
-	c.cfg.Client = cf
 	return cf, nil
 }
 
@@ -106,7 +191,11 @@ func (c *CloudFoundryProvider) ListApps() (map[string][]any, error) {
 		}
 		return apps, nil
 	}
-	return c.listAppsFromCloudFoundry()
+	apps, err := c.listAppsFromCloudFoundry()
+	if err != nil {
+		return nil, wrapAPIError(err)
+	}
+	return apps, nil
 }
 
 // AppReference represents a discovered application with its organizational context.
@@ -136,22 +225,15 @@ func (c *CloudFoundryProvider) listAppsFromLocalManifests() (map[string][]any, e
 	orgName := defaultLocalOrg
 
 	c.logger.Info("Using manifest path for Cloud Foundry local discover", "manifest_path", c.cfg.ManifestPath)
-	isDirResult, err := isDir(c.cfg.ManifestPath)
+	manifests, search, err := c.localManifests()
 	if err != nil {
-		return nil, fmt.Errorf("error checking if path is directory %s: %v", c.cfg.ManifestPath, err)
+		return nil, err
 	}
 
 	var apps []AppReference
 
-	if isDirResult {
-		files, err := os.ReadDir(c.cfg.ManifestPath)
-		if err != nil {
-			return nil, fmt.Errorf("error reading directory %s: %v", c.cfg.ManifestPath, err)
-		}
-
-		for _, file := range files {
-			filePath := filepath.Join(c.cfg.ManifestPath, file.Name())
-
+	if search {
+		for _, filePath := range manifests {
 			appName, spaceName, err := c.getAppNameAndSpaceFromManifest(filePath)
 			if err != nil {
 				c.logger.Info("error processing manifest file", "file_path", filePath, "error", err)
@@ -171,7 +253,7 @@ func (c *CloudFoundryProvider) listAppsFromLocalManifests() (map[string][]any, e
 	} else {
 		appName, spaceName, err := c.getAppNameAndSpaceFromManifest(c.cfg.ManifestPath)
 		if err != nil {
-			return nil, fmt.Errorf("error processing manifest file %s: %v", c.cfg.ManifestPath, err)
+			return nil, fmt.Errorf("error processing manifest file %s: %w", c.cfg.ManifestPath, err)
 		}
 		if appName == "" {
 			return nil, fmt.Errorf("no app name found in manifest file %s", c.cfg.ManifestPath)
@@ -194,13 +276,15 @@ func (c *CloudFoundryProvider) listAppsFromLocalManifests() (map[string][]any, e
 // getAppNameAndSpaceFromManifest extracts the app name and space name from a manifest file.
 // Returns (appName, spaceName, error). SpaceName defaults to "local" if not specified in manifest.
 func (c *CloudFoundryProvider) getAppNameAndSpaceFromManifest(filePath string) (string, string, error) {
-	info, err := os.Stat(filePath)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to stat file %q: %v", filePath, err)
-	}
-	if info.IsDir() {
-		c.logger.Info("Skipping directory", "path", filePath)
-		return "", "", nil
+	if _, _, ok := splitArchivePath(filePath); !ok {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to stat file %q: %w", filePath, err)
+		}
+		if info.IsDir() {
+			c.logger.Info("Skipping directory", "path", filePath)
+			return "", "", nil
+		}
 	}
 
 	// Check file extension for YAML
@@ -211,23 +295,25 @@ func (c *CloudFoundryProvider) getAppNameAndSpaceFromManifest(filePath string) (
 
 	c.logger.Info("Processing file.", "filename", filePath)
 
-	data, err := os.ReadFile(filePath)
+	doc, err := loadManifest(filePath)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to read manifest file %q: %v", filePath, err)
+		return "", "", err
 	}
 
-	var manifest cfTypes.AppManifest
-	if err := yaml.Unmarshal(data, &manifest); err != nil {
-		c.logger.Info("Failed to parse as single application manifest, will try Cloud Foundry manifest format", "file_path", filePath, "error", err)
-	} else if manifest.Name != "" {
-		c.logger.Info("Successfully parsed single application manifest", "file_path", filePath, "app_name", manifest.Name)
-		return manifest.Name, defaultLocalSpace, nil
+	if !doc.hasApplications() {
+		var manifest cfTypes.AppManifest
+		if err := doc.decode(&manifest); err != nil {
+			c.logger.Info("Failed to parse as single application manifest, will try Cloud Foundry manifest format", "file_path", filePath, "error", err)
+		} else if manifest.Name != "" {
+			c.logger.Info("Successfully parsed single application manifest", "file_path", filePath, "app_name", manifest.Name)
+			return manifest.Name, defaultLocalSpace, nil
+		}
+		c.logger.Info("Single application manifest parsed but no app name found, trying Cloud Foundry manifest format", "file_path", filePath)
 	}
-	c.logger.Info("Single application manifest parsed but no app name found, trying Cloud Foundry manifest format", "file_path", filePath)
 
 	var cfManifest cfTypes.CloudFoundryManifest
-	if err := yaml.Unmarshal(data, &cfManifest); err != nil {
-		return "", "", fmt.Errorf("failed to unmarshal YAML: %v", err)
+	if err := doc.decode(&cfManifest); err != nil {
+		return "", "", err
 	}
 	if len(cfManifest.Applications) == 0 {
 		return "", "", fmt.Errorf("no applications found in %s", filePath)
@@ -235,7 +321,7 @@ func (c *CloudFoundryProvider) getAppNameAndSpaceFromManifest(filePath string) (
 
 	c.logger.Info("Successfully parsed Cloud Foundry manifest", "file_path", filePath, "application_count", len(cfManifest.Applications))
 
-	app, err := parseCFApp(cfManifest.Space, *cfManifest.Applications[0])
+	app, _, err := c.parseApplication(cfManifest.Space, *cfManifest.Applications[0])
 	if err != nil {
 		return "", "", err
 	}
@@ -262,11 +348,14 @@ func (c *CloudFoundryProvider) listAppsFromCloudFoundry() (map[string][]any, err
 	}
 
 	appListByOrg := make(map[string][]any, len(c.cfg.OrgNames))
+	// Each listing maps the routes of the foundation again, without the applications and routes of the previous ones
+	c.appNames = map[string]string{}
+	c.routeMap = map[string][]RouteDestination{}
 
 	// Get all organizations by their names
 	orgs, err := c.getOrgsByNames(c.cfg.OrgNames)
 	if err != nil {
-		return nil, fmt.Errorf("error getting organizations: %v", err)
+		return nil, fmt.Errorf("error getting organizations: %w", err)
 	}
 
 	if len(orgs) == 0 {
@@ -277,7 +366,7 @@ func (c *CloudFoundryProvider) listAppsFromCloudFoundry() (map[string][]any, err
 	// Get all spaces filtered by org GUIDs and space names in a single API call
 	spaces, err := c.getSpacesByNamesAndOrgs(c.cfg.SpaceNames, orgs)
 	if err != nil {
-		return nil, fmt.Errorf("error getting spaces: %v", err)
+		return nil, fmt.Errorf("error getting spaces: %w", err)
 	}
 
 	c.logger.Info("Discovered spaces", "count", len(spaces), "orgs", len(orgs))
@@ -349,7 +438,7 @@ func (c *CloudFoundryProvider) processAppsInSpace(org *resource.Organization, sp
 
 	apps, err := c.listAppsBySpace(space, org.GUID)
 	if err != nil {
-		return fmt.Errorf("error listing Cloud Foundry apps for space %s: %v", space.Name, err)
+		return fmt.Errorf("error listing Cloud Foundry apps for space %s: %w", space.Name, err)
 	}
 
 	c.logger.Info("Apps discovered", "count", len(apps), "org_name", org.Name, "space_name", space.Name)
@@ -359,6 +448,7 @@ func (c *CloudFoundryProvider) processAppsInSpace(org *resource.Organization, sp
 			c.logger.Info("Skipping nil app reference")
 			continue
 		}
+		c.appNames[app.GUID] = app.Name
 		appRef := AppReference{
 			OrgName:   org.Name,
 			SpaceName: space.Name,
@@ -367,6 +457,9 @@ func (c *CloudFoundryProvider) processAppsInSpace(org *resource.Organization, sp
 		appListByOrg[org.Name] = append(appListByOrg[org.Name], appRef)
 	}
 
+	if c.cfg.MapRoutes {
+		return c.mapSpaceRoutes(space)
+	}
 	return nil
 }
 
@@ -412,6 +505,47 @@ func isDir(path string) (bool, error) {
 	return info.IsDir(), nil
 }
 
+// localManifests returns the manifests to discover from the manifest path, and whether they must be searched for the
+// application. When the path is a directory they are its files and the manifests contained in its archives, and when
+// the path is an archive they are the manifests contained in it.
+func (c *CloudFoundryProvider) localManifests() ([]string, bool, error) {
+	isDirResult, err := isDir(c.cfg.ManifestPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("error checking if path is directory %s: %w", c.cfg.ManifestPath, err)
+	}
+	if !isDirResult {
+		if !isArchive(c.cfg.ManifestPath) {
+			return []string{c.cfg.ManifestPath}, false, nil
+		}
+		manifests, err := archiveManifests(c.cfg.ManifestPath)
+		if err != nil {
+			return nil, false, err
+		}
+		c.logger.Info("Found manifests in archive", "archive", c.cfg.ManifestPath, "count", len(manifests))
+		return manifests, true, nil
+	}
+	files, err := os.ReadDir(c.cfg.ManifestPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading directory %s: %w", c.cfg.ManifestPath, err)
+	}
+	var manifests []string
+	for _, file := range files {
+		filePath := filepath.Join(c.cfg.ManifestPath, file.Name())
+		if file.IsDir() || !isArchive(file.Name()) {
+			manifests = append(manifests, filePath)
+			continue
+		}
+		m, err := archiveManifests(filePath)
+		if err != nil {
+			c.logger.Info("error reading archive", "file_path", filePath, "error", err)
+			continue
+		}
+		c.logger.Info("Found manifests in archive", "archive", filePath, "count", len(m))
+		manifests = append(manifests, m...)
+	}
+	return manifests, true, nil
+}
+
 // hasYAMLExtension checks if the given filename has a YAML file extension (.yaml or .yml).
 func hasYAMLExtension(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -425,21 +559,14 @@ func (c *CloudFoundryProvider) discoverFromManifest(appName string) (*pTypes.Dis
 
 	c.logger.Info("Manifest path provided, using it for local Cloud Foundry discover")
 
-	isDirResult, err := isDir(c.cfg.ManifestPath)
+	manifests, search, err := c.localManifests()
 	if err != nil {
-		return nil, fmt.Errorf("error checking if path is directory %s: %v", c.cfg.ManifestPath, err)
+		return nil, err
 	}
 	var manifestFile string
 
-	if isDirResult {
-		files, err := os.ReadDir(c.cfg.ManifestPath)
-		if err != nil {
-			return nil, fmt.Errorf("error reading directory %s: %v", c.cfg.ManifestPath, err)
-		}
-
-		for _, file := range files {
-			filePath := filepath.Join(c.cfg.ManifestPath, file.Name())
-
+	if search {
+		for _, filePath := range manifests {
 			name, spaceName, err := c.getAppNameAndSpaceFromManifest(filePath)
 			if err != nil {
 				c.logger.Info("error processing manifest file", "file_path", filePath, "error", err)
@@ -449,28 +576,43 @@ func (c *CloudFoundryProvider) discoverFromManifest(appName string) (*pTypes.Dis
 				c.logger.Info("manifest file does not contain an app name", "file_path", filePath)
 				continue
 			}
-			if name != appName {
+			// The first application of an archive is discovered when no name is given
+			if name != appName && (appName != "" || !isArchive(c.cfg.ManifestPath)) {
 				continue
 			}
 			manifestFile = filePath
 			c.logger.Info("found app in manifest file", "app_name", appName, "space_name", spaceName, "file_path", manifestFile)
 			break
 		}
+		if manifestFile == "" {
+			return nil, fmt.Errorf("%w: no manifest found for application %s in %s", ErrAppNotFound, appName, c.cfg.ManifestPath)
+		}
 	} else {
 		manifestFile = c.cfg.ManifestPath
 	}
 
-	d, err := c.discoverFromManifestFile(manifestFile)
+	d, warnings, err := c.discoverFromManifestFile(manifestFile)
 	if err != nil {
-		return nil, fmt.Errorf("error discovering from Cloud Foundry manifest file: %v", err)
+		return nil, fmt.Errorf("error discovering from Cloud Foundry manifest file: %w", err)
+	}
+	if c.cfg.InspectSource {
+		warnings = append(warnings, c.inspectApplicationSource(d, manifestFile)...)
 	}
+	c.resolveContainerImage(d)
+	c.detectMigrationHints(d)
+	discoverResult.Warnings = warnings
 	// Extract sensitive information and use UUID as references to the map[string]any structure that contains
 	// the original values
 	s := c.extractSensitiveInformation(d)
 	discoverResult.Secret = s
-	discoverResult.Content, err = structToMap(d)
+	metadata := DocumentMetadata{
+		Source:       LocalDiscoverySource,
+		ManifestPath: manifestFile,
+	}
+	metadata.Archive, metadata.ArchiveEntry, _ = splitArchivePath(manifestFile)
+	discoverResult.Content, err = c.discoverContent(d, metadata)
 	if err != nil {
-		return nil, fmt.Errorf("error converting discovered Cloud Foundry application to map: %v", err)
+		return nil, fmt.Errorf("error converting discovered Cloud Foundry application to map: %w", err)
 	}
 
 	return &discoverResult, nil
@@ -484,21 +626,29 @@ func (c *CloudFoundryProvider) discoverFromLive(orgName string, spaceName string
 	if appName == "" {
 		return nil, fmt.Errorf("no app GUID provided for Cloud Foundry live discover")
 	}
-	if c.cfg.CloudFoundryConfig == nil {
+	if c.cfConfig == nil {
 		return nil, fmt.Errorf("missing required configuration: APIEndpoint and CloudFoundryConfigPath must be provided for Cloud Foundry live discover")
 	}
 
 	c.logger.Info("Starting live Cloud Foundry discovery for app", "app_name", appName)
 
-	d, err := c.discoverFromLiveAPI(orgName, spaceName, appName)
+	d, warnings, err := c.discoverFromLiveAPI(orgName, spaceName, appName)
 	if err != nil {
-		return nil, err
+		return nil, wrapAPIError(err)
 	}
+	c.resolveContainerImage(d)
+	c.detectMigrationHints(d)
+	discoverResult.Warnings = warnings
 	// Extract sensitive information and use UUID as references to the map[string]any structure that contains
 	// the original values
 	s := c.extractSensitiveInformation(d)
 	discoverResult.Secret = s
-	discoverResult.Content, err = structToMap(d)
+	discoverResult.Content, err = c.discoverContent(d, DocumentMetadata{
+		Source:       LiveDiscoverySource,
+		APIEndpoint:  c.cfConfig.ApiURL(""),
+		Organization: orgName,
+		Space:        spaceName,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -506,6 +656,28 @@ func (c *CloudFoundryProvider) discoverFromLive(orgName string, spaceName string
 	return &discoverResult, nil
 }
 
+// resolveContainerImage sets the container images recommended for the application when image resolution is enabled.
+func (c *CloudFoundryProvider) resolveContainerImage(app *Application) {
+	if c.catalog == nil {
+		return
+	}
+	app.ContainerImage = c.catalog.Resolve(*app)
+	if app.ContainerImage != nil {
+		c.logger.Info("Resolved the container image of the application", "app_name", app.Name, "entry", app.ContainerImage.Entry, "base_image", app.ContainerImage.BaseImage, "unmapped_buildpacks", app.ContainerImage.UnmappedBuildpacks)
+	}
+}
+
+// discoverContent converts the discovered application into the content of the discovery result: a DiscoveryDocument
+// with the given provenance metadata, or the bare application when BareOutput is set.
+func (c *CloudFoundryProvider) discoverContent(app *Application, metadata DocumentMetadata) (map[string]any, error) {
+	if c.cfg.BareOutput {
+		return structToMap(app)
+	}
+	now := time.Now().UTC()
+	metadata.DiscoveredAt = &now
+	return structToMap(NewDiscoveryDocument(*app, metadata))
+}
+
 // discoverFromManifestFile reads a manifest file and returns a list of applications.
 //
 // If an output folder is specified:
@@ -514,57 +686,103 @@ func (c *CloudFoundryProvider) discoverFromLive(orgName string, spaceName string
 //
 // If no output folder is specified:
 //   - The function returns the list of applications parsed from the manifest.
-func (c *CloudFoundryProvider) discoverFromManifestFile(filePath string) (*Application, error) {
-	data, err := os.ReadFile(filePath)
+func (c *CloudFoundryProvider) discoverFromManifestFile(filePath string) (*Application, []pTypes.Warning, error) {
+	doc, err := loadManifest(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read manifest file: %v", err)
+		return nil, nil, err
 	}
-	var manifest cfTypes.AppManifest
-
-	if err = yaml.Unmarshal(data, &manifest); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal YAML: %v", err)
+	spaceName, cfApp, err := manifestApplication(doc)
+	if err != nil {
+		return nil, nil, err
 	}
+	app, warnings, err := c.parseApplication(spaceName, cfApp)
+	if err != nil {
+		locateValidationError(err, app, doc)
+		return nil, nil, fmt.Errorf("failed to create application: %w", err)
+	}
+	if archive, entry, ok := splitArchivePath(filePath); ok {
+		app.Path = archiveApplicationPath(archive, entry, app.Path)
+	}
+	return &app, warnings, nil
+}
+
+// manifestApplication returns the application to discover from the manifest and its space: the manifest itself when
+// it contains a single application, otherwise the first application of the list.
+func manifestApplication(doc *manifestDocument) (string, cfTypes.AppManifest, error) {
 	// Check if the file contains a single application that does not contain a space
-	if !reflect.DeepEqual(manifest, cfTypes.AppManifest{}) {
-		app, err := parseCFApp("", manifest)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create application: %v", err)
+	if !doc.hasApplications() {
+		var manifest cfTypes.AppManifest
+		if err := doc.decode(&manifest); err != nil {
+			return "", manifest, err
+		}
+		if !reflect.DeepEqual(manifest, cfTypes.AppManifest{}) {
+			return "", manifest, nil
 		}
-		return &app, nil
 	}
-	c.logger.Info("Failed to parse Application manifest. Will attempt again using the Cloud Foundry Application manifest", "error", err)
 	var cfManifest cfTypes.CloudFoundryManifest
-	if err := yaml.Unmarshal(data, &cfManifest); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal YAML: %v", err)
+	if err := doc.decode(&cfManifest); err != nil {
+		return "", cfTypes.AppManifest{}, err
 	}
 	if len(cfManifest.Applications) == 0 {
-		return nil, fmt.Errorf("no applications found in %s", filePath)
+		return "", cfTypes.AppManifest{}, fmt.Errorf("%w: no applications found in %s", ErrAppNotFound, doc.file)
 	}
-	c.logger.Info("Found applications in manifest", "count", len(cfManifest.Applications), "file", filePath)
-	app, err := parseCFApp(cfManifest.Space, *cfManifest.Applications[0])
+	return cfManifest.Space, *cfManifest.Applications[0], nil
+}
+
+// discoverFromLiveAPI retrieves the application from the live API with the configured discovery strategy.
+func (c *CloudFoundryProvider) discoverFromLiveAPI(orgName string, spaceName string, appName string) (*Application, []pTypes.Warning, error) {
+	c.logger.Info("Analyzing application", "app_name", appName, "strategy", c.cfg.Strategy)
+	app, err := c.getAppByOrgAndSpaceAndAppName(orgName, spaceName, appName)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	switch c.cfg.Strategy {
+	case ManifestDiscoveryStrategy:
+		return c.discoverFromGeneratedManifest(spaceName, app)
+	case CombinedDiscoveryStrategy:
+		return c.discoverCombined(spaceName, app)
 	}
-	return &app, nil
+	return c.discoverFromResources(spaceName, app)
 }
 
-// discoverFromLiveAPI retrieves the application manifests from the live API
-// and returns a list of applications.
-// If the output folder is provided, it writes the manifest to a file in the
-// output folder with the name "manifest_<space_name>_<app_name>.yaml".
-// If the output folder is not provided, it returns a list of applications.
-func (c *CloudFoundryProvider) discoverFromLiveAPI(orgName string, spaceName string, appName string) (*Application, error) {
-	cfManifests, err := c.generateCFManifestFromLiveAPI(orgName, spaceName, appName)
+// discoverFromResources builds the application from its resources in the live API.
+func (c *CloudFoundryProvider) discoverFromResources(spaceName string, app *resource.App) (*Application, []pTypes.Warning, error) {
+	cfManifest, details, err := c.getApplicationResources(app)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	discoveredApp, err := parseCFApp(spaceName, *cfManifests)
+	discoveredApp, warnings, err := c.parseApplication(spaceName, *cfManifest)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	warnings = append(warnings, details.warnings...)
+	warnings = append(warnings, applyAutoscalingPolicy(&discoveredApp, details.autoscaling)...)
+	applyRouteDetails(&discoveredApp, details.routes)
+	applyServiceLabels(&discoveredApp, details.serviceLabels)
+	discoveredApp.SSH = details.ssh
+	discoveredApp.Revisions = details.revisions
+	discoveredApp.Droplet = details.droplet
+
+	return &discoveredApp, warnings, nil
+}
 
-	return &discoveredApp, nil
+// parseApplication parses the Cloud Foundry application manifest into an Application. In lenient mode, the values
+// that do not satisfy the validation constraints are adjusted or dropped and reported as warnings instead of failing.
+func (c *CloudFoundryProvider) parseApplication(spaceName string, cfApp cfTypes.AppManifest) (Application, []pTypes.Warning, error) {
+	app, err := parseCFApp(spaceName, cfApp)
+	var vErr *ValidationError
+	if err == nil || !c.cfg.Lenient || !errors.As(err, &vErr) {
+		return app, nil, err
+	}
+	warnings, err := sanitizeApplication(&app, vErr)
+	if err != nil {
+		return app, nil, err
+	}
+	for _, w := range warnings {
+		c.logger.Info("Adjusted invalid value of the discovered application", "app_name", app.Name, "path", w.Path, "value", w.Value, "message", w.Message)
+	}
+	return app, warnings, nil
 }
 
 // getProcesses retrieves process information for the specified Cloud Foundry application.
@@ -572,7 +790,7 @@ func (c *CloudFoundryProvider) discoverFromLiveAPI(orgName string, spaceName str
 func (c *CloudFoundryProvider) getProcesses(appGUID, lifecycle string) (*cfTypes.AppManifestProcesses, error) {
 	processes, err := c.cli.Processes.ListForAppAll(context.Background(), appGUID, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error getting processes: %v", err)
+		return nil, fmt.Errorf("error getting processes: %w", err)
 	}
 
 	if len(processes) == 0 {
@@ -583,7 +801,7 @@ func (c *CloudFoundryProvider) getProcesses(appGUID, lifecycle string) (*cfTypes
 		procInstances := uint(proc.Instances)
 		resourceProcess, err := c.cli.Processes.Get(context.Background(), proc.GUID)
 		if err != nil {
-			return nil, fmt.Errorf("error getting process %s: %v", proc.GUID, err)
+			return nil, fmt.Errorf("error getting process %s: %w", proc.GUID, err)
 		}
 		appProcesses = append(appProcesses, cfTypes.AppManifestProcess{
 			Type:                             cfTypes.AppProcessType(proc.Type),
@@ -609,79 +827,240 @@ func (c *CloudFoundryProvider) getProcesses(appGUID, lifecycle string) (*cfTypes
 
 // getRoutes retrieves route information for the specified Cloud Foundry application.
 // Returns route configurations including URLs, protocols, and options.
-func (c *CloudFoundryProvider) getRoutes(appGUID string) (*cfTypes.AppManifestRoutes, error) {
+// The destinations of the applications whose name can't be retrieved keep their GUID and are reported as warnings.
+func (c *CloudFoundryProvider) getRoutes(app *resource.App) (*cfTypes.AppManifestRoutes, map[string]routeDetails, []pTypes.Warning, error) {
+	c.appNames[app.GUID] = app.Name
 	routeOpts := client.NewRouteListOptions()
-	routes, err := c.cli.Routes.ListForAppAll(context.Background(), appGUID, routeOpts)
+	routes, err := c.cli.Routes.ListForAppAll(context.Background(), app.GUID, routeOpts)
 	if err != nil {
-		return nil, fmt.Errorf("error getting processes: %v", err)
+		return nil, nil, nil, fmt.Errorf("error getting processes: %w", err)
 	}
 	appRoutes := cfTypes.AppManifestRoutes{}
+	details := map[string]routeDetails{}
+	var warnings []pTypes.Warning
 	for _, r := range routes {
 		destinations, err := c.cli.Routes.GetDestinations(context.Background(), r.GUID)
 		if err != nil {
-			return nil, fmt.Errorf("error getting destinations for route %s: %v", r.GUID, err)
-		}
-		var protocol string
-		if len(destinations.Destinations) > 0 {
-			protocol = *destinations.Destinations[0].Protocol
+			return nil, nil, nil, fmt.Errorf("error getting destinations for route %s: %w", r.GUID, err)
 		}
 		var options *cfTypes.AppRouteOptions
 		if r.Options != nil {
 			options = &cfTypes.AppRouteOptions{LoadBalancing: r.Options.LoadBalancing}
+			if r.Options.LoadBalancing == string(HashLoadBalancingType) {
+				if options, err = c.getRouteOptions(r.GUID); err != nil {
+					return nil, nil, nil, err
+				}
+			}
+		}
+		ds := make([]RouteDestination, 0, len(destinations.Destinations))
+		for _, d := range destinations.Destinations {
+			dest, err := c.routeDestination(*d)
+			if err != nil {
+				warnings = append(warnings, pTypes.Warning{
+					Path:    fmt.Sprintf("routes.routes[%s].destinations", r.URL),
+					Value:   dest.App,
+					Message: fmt.Sprintf("the name of the application of the destination is not known: %s", err),
+				})
+			}
+			ds = append(ds, dest)
 		}
 		appRoutes = append(appRoutes, cfTypes.AppManifestRoute{
 			Route:    r.URL,
-			Protocol: cfTypes.AppRouteProtocol(protocol),
+			Protocol: cfTypes.AppRouteProtocol(destinationProtocol(app.Name, ds)),
 			Options:  options,
 		})
+		details[r.URL] = routeDetails{destinations: ds}
+	}
+	if len(routes) == 0 {
+		return &appRoutes, details, warnings, nil
 	}
-	return &appRoutes, nil
+	if err := c.getRouteServices(routes, details); err != nil {
+		return nil, nil, nil, err
+	}
+	return &appRoutes, details, warnings, nil
 }
 
-// generateCFManifestFromLiveAPI generates a Cloud Foundry manifest by querying the live API.
-// It retrieves complete application configuration including processes, routes, services, and sidecars.
-func (c *CloudFoundryProvider) generateCFManifestFromLiveAPI(orgName string, spaceName string, appName string) (*cfTypes.AppManifest, error) {
-
-	c.logger.Info("Analyzing application", "app_name", appName)
+// routeOptions are the options of a route as returned by the Cloud Foundry API, including the hash based load
+// balancing options that the client does not decode.
+type routeOptions struct {
+	Options struct {
+		LoadBalancing string      `json:"loadbalancing"`
+		HashHeader    string      `json:"hash_header"`
+		HashBalance   json.Number `json:"hash_balance"`
+	} `json:"options"`
+}
 
-	// Retrieve app in space and app name
-	app, err := c.getAppByOrgAndSpaceAndAppName(orgName, spaceName, appName)
+// getRouteOptions retrieves the options of a route that uses hash based load balancing.
+func (c *CloudFoundryProvider) getRouteOptions(routeGUID string) (*cfTypes.AppRouteOptions, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, c.cfConfig.ApiURL("/v3/routes/"+routeGUID), nil)
 	if err != nil {
 		return nil, err
 	}
+	resp, err := c.cli.ExecuteAuthRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting options for route %s: %w", routeGUID, err)
+	}
+	defer resp.Body.Close()
+	var r routeOptions
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to decode the options of route %s: %w", routeGUID, err)
+	}
+	return &cfTypes.AppRouteOptions{
+		LoadBalancing: r.Options.LoadBalancing,
+		HashHeader:    r.Options.HashHeader,
+		HashBalance:   r.Options.HashBalance.String(),
+	}, nil
+}
+
+// getRouteServices retrieves the URLs of the route services bound to the routes.
+func (c *CloudFoundryProvider) getRouteServices(routes []*resource.Route, details map[string]routeDetails) error {
+	urls := make(map[string]string, len(routes))
+	opts := client.NewServiceRouteBindingListOptions()
+	for _, r := range routes {
+		urls[r.GUID] = r.URL
+		opts.RouteGUIDs.EqualTo(r.GUID)
+	}
+	bindings, err := c.cli.ServiceRouteBindings.ListAll(context.Background(), opts)
+	if err != nil {
+		return fmt.Errorf("error getting route services: %w", err)
+	}
+	for _, b := range bindings {
+		if b.Relationships.Route.Data == nil {
+			continue
+		}
+		routeURL, ok := urls[b.Relationships.Route.Data.GUID]
+		if !ok {
+			continue
+		}
+		d := details[routeURL]
+		d.serviceURL = b.RouteServiceURL
+		details[routeURL] = d
+	}
+	return nil
+}
+
+// routeDetails contains the information of a live route that the Cloud Foundry manifest cannot represent.
+type routeDetails struct {
+	// serviceURL is the URL of the route service bound to the route.
+	serviceURL string
+	// destinations contains the destinations of the route, including those of other applications.
+	destinations []RouteDestination
+}
+
+// applyRouteDetails sets the route service and the destinations of the application routes.
+func applyRouteDetails(app *Application, details map[string]routeDetails) {
+	for i, r := range app.Routes.Routes {
+		d, ok := details[r.Route]
+		if !ok {
+			continue
+		}
+		app.Routes.Routes[i].RouteServiceURL = d.serviceURL
+		app.Routes.Routes[i].Destinations = d.destinations
+		app.Routes.Routes[i].Shared = isSharedRoute(d.destinations)
+	}
+}
+
+// applyServiceLabels sets the service offering of the application services.
+func applyServiceLabels(app *Application, labels map[string]string) {
+	for i, svc := range app.Services {
+		app.Services[i].Label = labels[svc.Name]
+	}
+}
+
+// liveDetails contains the information of a live application that the Cloud Foundry manifest cannot represent.
+type liveDetails struct {
+	// autoscaling is the App Autoscaler policy of the application.
+	autoscaling *AutoscalingPolicy
+	// routes contains the route services and destinations of the application routes, by route URL.
+	routes map[string]routeDetails
+	// serviceLabels contains the service offering of the application services, by instance name.
+	serviceLabels map[string]string
+	// ssh reports whether the runtime of the application accepts SSH connections.
+	ssh *SSHSettings
+	// revisions contains the deployed revisions of the application.
+	revisions []Revision
+	// droplet contains the current droplet of the application and the package it was staged from.
+	droplet *Droplet
+	// warnings contains the information that could not be retrieved without failing the discovery.
+	warnings []pTypes.Warning
+}
+
+// warn reports the information of the application that could not be retrieved as a warning, in the field at the path.
+func (d *liveDetails) warn(path string, err error) {
+	d.warnings = append(d.warnings, pTypes.Warning{Path: path, Message: err.Error()})
+}
+
+// getApplicationResources builds the Cloud Foundry manifest of the live application from its resources: environment,
+// processes, routes, sidecars, droplet and features.
+func (c *CloudFoundryProvider) getApplicationResources(app *resource.App) (*cfTypes.AppManifest, *liveDetails, error) {
+	var details liveDetails
 
 	c.logger.Info("Processing app", "app_name", app.Name)
 	appEnv, err := c.cli.Applications.GetEnvironment(context.Background(), app.GUID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	appProcesses, err := c.getProcesses(app.GUID, string(app.Lifecycle.Type))
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	appRoutes, err := c.getRoutes(app.GUID)
+	appRoutes, routes, warnings, err := c.getRoutes(app)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	details.routes = routes
+	details.warnings = append(details.warnings, warnings...)
 
 	c.cli.ServiceCredentialBindings.GetParameters(context.Background(), app.GUID)
+
+	var autoscalingWarnings []pTypes.Warning
+	details.autoscaling, autoscalingWarnings = c.discoverAutoscalingPolicy(app.GUID, appEnv.SystemEnvVars)
+	details.warnings = append(details.warnings, autoscalingWarnings...)
 	// Sidecars
 	sidecars, err := c.getSidecars(app.GUID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	// The features, the SSH status and the revisions complete the application without being required to run it, so
+	// failing to retrieve them, e.g. without the permission to read them, is reported as a warning
+	features, err := c.getFeatures(app.GUID)
+	if err != nil {
+		details.warn("features", fmt.Errorf("failed to retrieve the features: %w", err))
+	}
+	details.ssh, err = c.getSSHSettings(app.GUID)
+	if err != nil {
+		details.warn("ssh", fmt.Errorf("failed to retrieve the SSH status: %w", err))
+	}
+	details.revisions, err = c.getRevisions(app.GUID, features)
+	if err != nil {
+		details.warn("revisions", fmt.Errorf("failed to retrieve the deployed revisions: %w", err))
+	}
+	if app.Lifecycle.Type != "docker" {
+		var dropletWarnings []pTypes.Warning
+		details.droplet, dropletWarnings = c.getDroplet(app.GUID)
+		details.warnings = append(details.warnings, dropletWarnings...)
 	}
 
 	// Retrieve services required by the application
 	appServices, err := getServicesFromApplicationEnvironment(appEnv.SystemEnvVars)
 	if err != nil {
-		return nil, fmt.Errorf("error getting services for app %s: %s", app.Name, err)
+		return nil, nil, fmt.Errorf("error getting services for app %s: %w", app.Name, err)
 	}
+	details.serviceLabels = getServiceLabels(appEnv.SystemEnvVars)
 	// Retrieve docker image pullspec when the buildpack is type docker
 	dockerSpec, err := c.getDockerSpecification(*app)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if dockerSpec != nil {
+		// The image can be pulled without the username, e.g. from a public registry
+		dockerSpec.Username, err = c.getDockerUsername(app.GUID)
+		if err != nil {
+			details.warn("docker.username", fmt.Errorf("failed to retrieve the docker package: %w", err))
+		}
 	}
 	appManifest := cfTypes.AppManifest{
 		Name:   app.Name,
@@ -703,14 +1082,15 @@ func (c *CloudFoundryProvider) generateCFManifestFromLiveAPI(orgName string, spa
 		Services: appServices,
 		Sidecars: sidecars,
 		Stack:    app.Lifecycle.BuildpackData.Stack,
+		Features: features,
 	}
 
-	return &appManifest, nil
+	return &appManifest, &details, nil
 
 }
 
 // getDockerSpecification retrieves Docker configuration for the specified application.
-// Returns Docker image information if the application uses Docker lifecycle, otherwise returns nil.
+// Returns Docker image if the application uses Docker lifecycle, otherwise returns nil.
 func (c *CloudFoundryProvider) getDockerSpecification(app resource.App) (*cfTypes.AppManifestDocker, error) {
 
 	docker := cfTypes.AppManifestDocker{}
@@ -719,7 +1099,10 @@ func (c *CloudFoundryProvider) getDockerSpecification(app resource.App) (*cfType
 	}
 	d, err := c.cli.Droplets.GetCurrentForApp(context.Background(), app.GUID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve droplet for app %s", app.Name)
+		return nil, fmt.Errorf("failed to retrieve droplet for app %s: %w", app.Name, err)
+	}
+	if d.Image == nil || *d.Image == "" {
+		return nil, fmt.Errorf("the current droplet of docker app %s has no image", app.Name)
 	}
 	docker.Image = *d.Image
 	return &docker, nil
@@ -730,7 +1113,7 @@ func (c *CloudFoundryProvider) getDockerSpecification(app resource.App) (*cfType
 func (c *CloudFoundryProvider) getSidecars(appGUID string) (*cfTypes.AppManifestSideCars, error) {
 	list, err := c.cli.Sidecars.ListForAppAll(context.Background(), appGUID, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error while retrieving sidecars for app %s: %s", appGUID, err)
+		return nil, fmt.Errorf("error while retrieving sidecars for app %s: %w", appGUID, err)
 	}
 	if len(list) == 0 {
 		return nil, nil
@@ -773,7 +1156,7 @@ func getServicesFromApplicationEnvironment(env map[string]json.RawMessage) (*cfT
 	instanceServices := map[string][]appVCAPServiceAttributes{}
 	err := json.Unmarshal(vcap, &instanceServices)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal VCAP_SERVICES: %s", err)
+		return nil, fmt.Errorf("failed to unmarshal VCAP_SERVICES: %w", err)
 	}
 	for _, services := range instanceServices {
 		for _, svc := range services {
@@ -789,9 +1172,29 @@ func getServicesFromApplicationEnvironment(env map[string]json.RawMessage) (*cfT
 			appServices = append(appServices, s)
 		}
 	}
+	// VCAP_SERVICES is decoded into a map, so the services are sorted to make the order independent of the iteration
+	slices.SortStableFunc(appServices, func(a, b cfTypes.AppManifestService) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.BindingName, b.BindingName))
+	})
 	return &appServices, nil
 }
 
+// getServiceLabels returns the service offering of the service instances of the application's VCAP_SERVICES environment
+// variable, by instance name. The offerings are the keys of VCAP_SERVICES.
+func getServiceLabels(env map[string]json.RawMessage) map[string]string {
+	instanceServices := map[string][]appVCAPServiceAttributes{}
+	if err := json.Unmarshal(env[vcapServices], &instanceServices); err != nil {
+		return nil
+	}
+	labels := map[string]string{}
+	for label, services := range instanceServices {
+		for _, svc := range services {
+			labels[svc.InstanceName] = label
+		}
+	}
+	return labels
+}
+
 // getSpaceByNameInOrg retrieves a space by name within a specific organization.
 // Returns an error if the space is not found or has invalid data.
 func (c *CloudFoundryProvider) getSpaceByNameInOrg(spaceName string, orgGUID string) (*resource.Space, error) {
@@ -800,7 +1203,7 @@ func (c *CloudFoundryProvider) getSpaceByNameInOrg(spaceName string, orgGUID str
 	spaceOpts.OrganizationGUIDs.EqualTo(orgGUID)
 	remoteSpace, err := c.cli.Spaces.First(context.Background(), spaceOpts)
 	if err != nil {
-		return nil, fmt.Errorf("error finding Cloud Foundry space for name '%s' in organization '%s': %v", spaceName, orgGUID, err)
+		return nil, fmt.Errorf("error finding Cloud Foundry space for name '%s' in organization '%s': %w", spaceName, orgGUID, err)
 	}
 	if remoteSpace == nil {
 		return nil, fmt.Errorf("Cloud Foundry API returned nil space for name '%s' in organization '%s'", spaceName, orgGUID)
@@ -834,7 +1237,7 @@ func (c *CloudFoundryProvider) getSpacesByNamesAndOrgs(spaceNames []string, orgs
 	spaceOpts.OrganizationGUIDs.EqualTo(orgGUIDs...)
 	spaces, err := c.cli.Spaces.ListAll(context.Background(), spaceOpts)
 	if err != nil {
-		return nil, fmt.Errorf("error listing Cloud Foundry spaces: %v", err)
+		return nil, fmt.Errorf("error listing Cloud Foundry spaces: %w", err)
 	}
 
 	return spaces, nil
@@ -847,7 +1250,7 @@ func (c *CloudFoundryProvider) getOrgByName(orgName string) (*resource.Organizat
 	orgOpts.Names.EqualTo(orgName)
 	remoteOrg, err := c.cli.Organizations.First(context.Background(), orgOpts)
 	if err != nil {
-		return nil, fmt.Errorf("error finding Cloud Foundry organization for name '%s': %v", orgName, err)
+		return nil, fmt.Errorf("error finding Cloud Foundry organization for name '%s': %w", orgName, err)
 	}
 	if remoteOrg == nil {
 		return nil, fmt.Errorf("Cloud Foundry API returned nil organization for name '%s'", orgName)
@@ -867,7 +1270,7 @@ func (c *CloudFoundryProvider) getOrgsByNames(orgNames []string) ([]*resource.Or
 	}
 	orgs, err := c.cli.Organizations.ListAll(context.Background(), orgOpts)
 	if err != nil {
-		return nil, fmt.Errorf("error listing Cloud Foundry organizations: %v", err)
+		return nil, fmt.Errorf("error listing Cloud Foundry organizations: %w", err)
 	}
 
 	return orgs, nil
@@ -891,7 +1294,7 @@ func (c *CloudFoundryProvider) listAppsBySpace(space *resource.Space, orgID stri
 	appsOpt.OrganizationGUIDs.EqualTo(orgID)
 	apps, err := c.cli.Applications.ListAll(context.Background(), appsOpt)
 	if err != nil {
-		return nil, fmt.Errorf("error listing Cloud Foundry apps for space name %s: %v", space.Name, err)
+		return nil, fmt.Errorf("error listing Cloud Foundry apps for space name %s: %w", space.Name, err)
 	}
 	return apps, nil
 }
@@ -916,13 +1319,13 @@ func (c *CloudFoundryProvider) getAppByOrgAndSpaceAndAppName(orgName string, spa
 
 	app, err := c.cli.Applications.ListAll(context.Background(), appsOpt)
 	if err != nil {
-		return nil, fmt.Errorf("error listing Cloud Foundry apps: %s", err)
+		return nil, fmt.Errorf("error listing Cloud Foundry apps: %w", err)
 	}
 	if len(app) == 0 {
-		return nil, fmt.Errorf("no application found with name %s in org %s and space %s", appName, orgName, spaceName)
+		return nil, fmt.Errorf("%w: no application found with name %s in org %s and space %s", ErrAppNotFound, appName, orgName, spaceName)
 	}
 	if len(app) > 1 {
-		return nil, fmt.Errorf("multiple applications found with name %s in org %s and space %s", appName, orgName, spaceName)
+		return nil, fmt.Errorf("%w: %d applications found with name %s in org %s and space %s", ErrAmbiguousApp, len(app), appName, orgName, spaceName)
 	}
 	return app[0], nil
 }
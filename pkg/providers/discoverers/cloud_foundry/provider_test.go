@@ -221,7 +221,16 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 				app1 = g.Application()
 			})
 
-			When("calling the generateCFManifestFromLiveAPI() function to generate the app manifest from a live connection", func() {
+			getApplicationManifest := func(p *CloudFoundryProvider, m mockApplication) (*cfTypes.AppManifest, error) {
+				app, err := p.getAppByOrgAndSpaceAndAppName(m.organization().Name, m.space().Name, m.application().Name)
+				if err != nil {
+					return nil, err
+				}
+				manifest, _, err := p.getApplicationResources(app)
+				return manifest, err
+			}
+
+			When("calling the getApplicationResources() function to generate the app manifest from a live connection", func() {
 				AfterEach(func() {
 					testutil.Teardown()
 				})
@@ -243,7 +252,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					p, err := New(cfConfig, &logger, true)
 					Expect(err).NotTo(HaveOccurred())
 					By("generating the CF manifest from a Live API connection")
-					received, err := p.generateCFManifestFromLiveAPI(m.organization().Name, m.space().Name, m.application().Name)
+					received, err := getApplicationManifest(p, m)
 					Expect(err).NotTo(HaveOccurred())
 					By("validating the application discovered contains the expected app data")
 					Expect(expected.Name).To(Equal(received.Name))
@@ -276,7 +285,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					p, err := New(cfConfig, &logger, true)
 					Expect(err).NotTo(HaveOccurred())
 					By("generating the CF manifest from a Live API connection")
-					received, err := p.generateCFManifestFromLiveAPI(m.organization().Name, m.space().Name, m.application().Name)
+					received, err := getApplicationManifest(p, m)
 					Expect(err).NotTo(HaveOccurred())
 					By("validating the application discovered contains the expected app data")
 					Expect(expected.Name).To(Equal(received.Name))
@@ -308,7 +317,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					p, err := New(cfConfig, &logger, true)
 					Expect(err).NotTo(HaveOccurred())
 					By("generating the CF manifest from a Live API connection")
-					received, err := p.generateCFManifestFromLiveAPI(m.organization().Name, m.space().Name, m.application().Name)
+					received, err := getApplicationManifest(p, m)
 					Expect(err).NotTo(HaveOccurred())
 					By("validating the application discovered contains the expected app data")
 					Expect(expected.Name).To(Equal(received.Name))
@@ -341,7 +350,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					p, err := New(cfConfig, &logger, true)
 					Expect(err).NotTo(HaveOccurred())
 					By("generating the CF manifest from a Live API connection")
-					received, err := p.generateCFManifestFromLiveAPI(m.organization().Name, m.space().Name, m.application().Name)
+					received, err := getApplicationManifest(p, m)
 					Expect(err).NotTo(HaveOccurred())
 					By("validating the application discovered contains the expected app data")
 					Expect(expected.Name).To(Equal(received.Name))
@@ -373,15 +382,16 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					p, err := New(cfConfig, &logger, true)
 					Expect(err).NotTo(HaveOccurred())
 					By("generating the CF manifest from a Live API connection")
-					received, err := p.generateCFManifestFromLiveAPI(m.organization().Name, m.space().Name, m.application().Name)
+					received, err := getApplicationManifest(p, m)
 					Expect(err).NotTo(HaveOccurred())
 					By("validating the application discovered contains the expected app data")
 					Expect(expected.Name).To(Equal(received.Name))
 					if docker == nil {
 						Expect(received.Docker).To(BeNil())
 					} else {
-						// Docker's username is not provided for runtime applications
 						Expect(received.Docker.Image).To(Equal(expected.Docker.Image))
+						By("reading the registry username from the docker package")
+						Expect(received.Docker.Username).To(Equal(expected.Docker.Username))
 					}
 				},
 					Entry("discovers an app with nil value", nil),
@@ -407,7 +417,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					p, err := New(cfConfig, &logger, true)
 					Expect(err).NotTo(HaveOccurred())
 					By("generating the CF manifest from a Live API connection")
-					received, err := p.generateCFManifestFromLiveAPI(m.organization().Name, m.space().Name, m.application().Name)
+					received, err := getApplicationManifest(p, m)
 					Expect(err).NotTo(HaveOccurred())
 					By("validating the application discovered contains the expected app data")
 					Expect(expected.Name).To(Equal(received.Name))
@@ -447,7 +457,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					p, err := New(cfConfig, &logger, true)
 					Expect(err).NotTo(HaveOccurred())
 					By("generating the CF manifest from a Live API connection")
-					received, err := p.generateCFManifestFromLiveAPI(m.organization().Name, m.space().Name, m.application().Name)
+					received, err := getApplicationManifest(p, m)
 					Expect(err).NotTo(HaveOccurred())
 					By("validating the application discovered contains the expected app data")
 					Expect(expected.Name).To(Equal(received.Name))
@@ -488,7 +498,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					p, err := New(cfConfig, &logger, true)
 					Expect(err).NotTo(HaveOccurred())
 					By("generating the CF manifest from a Live API connection")
-					received, err := p.generateCFManifestFromLiveAPI(m.organization().Name, m.space().Name, m.application().Name)
+					received, err := getApplicationManifest(p, m)
 					Expect(err).NotTo(HaveOccurred())
 					By("validating the application discovered contains the expected app data")
 					Expect(expected.Name).To(Equal(received.Name))
@@ -544,7 +554,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					p, err := New(cfConfig, &logger, true)
 					Expect(err).NotTo(HaveOccurred())
 					By("generating the CF manifest from a Live API connection")
-					received, err := p.generateCFManifestFromLiveAPI(m.organization().Name, m.space().Name, m.application().Name)
+					received, err := getApplicationManifest(p, m)
 					Expect(err).NotTo(HaveOccurred())
 					By("validating the application discovered contains the expected app data")
 					Expect(expected.Name).To(Equal(received.Name))
@@ -718,7 +728,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					p, err := New(cfConfig, &logger, true)
 					Expect(err).NotTo(HaveOccurred())
 					By("discovering the application")
-					received, err := p.generateCFManifestFromLiveAPI(m.organization().Name, m.space().Name, m.application().Name)
+					received, err := getApplicationManifest(p, m)
 					Expect(err).NotTo(HaveOccurred())
 					By("validating the application discovered contains the expected app data")
 					Expect(*received).To(Equal(expected))
@@ -1046,7 +1056,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 				})
 
 				It("successfully parses a valid manifest and returns an Application", func() {
-					app, err := provider.discoverFromManifestFile(manifestPath)
+					app, _, err := provider.discoverFromManifestFile(manifestPath)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(app).ToNot(BeNil())
 					Expect(app.Metadata).ToNot(BeNil())
@@ -1054,7 +1064,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 				})
 
 				It("returns an error if the manifest file does not exist", func() {
-					app, err := provider.discoverFromManifestFile("/not/exist/manifest")
+					app, _, err := provider.discoverFromManifestFile("/not/exist/manifest")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("failed to read manifest file"))
 					Expect(app).To(BeNil())
@@ -1062,7 +1072,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 
 				It("returns an error if the manifest YAML is invalid", func() {
 					invalidManifestPath := filepath.Join("test_data", "invalid-manifest", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(invalidManifestPath)
+					app, _, err := provider.discoverFromManifestFile(invalidManifestPath)
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("failed to unmarshal YAML"))
 					Expect(app).To(BeNil())
@@ -1078,7 +1088,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					}
 					parseCFApp = mockParseCF
 
-					app, err := provider.discoverFromManifestFile(manifestPath)
+					app, _, err := provider.discoverFromManifestFile(manifestPath)
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("failed to create application"))
 					Expect(app).To(BeNil())
@@ -1086,7 +1096,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 				It("parses correctly the probes from an inlined process spec", func() {
 					expected := Application{
 						Metadata: Metadata{Name: "app-with-inline-process"},
-						Docker:   Docker{Image: "myregistry/myapp:latest"},
+						Docker:   Docker{Image: "myregistry/myapp:latest", Registry: "docker.io"},
 						Processes: Processes{
 							{
 								Type: Web,
@@ -1110,7 +1120,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "process_manifest", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1119,8 +1129,10 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 					expected := Application{
 						Metadata: Metadata{Name: "app-with-inline-process-only-type"},
 						Docker: Docker{
-							Image:    "myregistry/myapp:latest",
-							Username: "docker-registry-user"},
+							Image:           "myregistry/myapp:latest",
+							Username:        "docker-registry-user",
+							Registry:        "docker.io",
+							PrivateRegistry: true},
 						Processes: Processes{
 							{
 								Type: Web,
@@ -1145,10 +1157,28 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "inline-process-with-type-only-manifest", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
+
+				It("applies the lifecycle of the application to the processes that do not define one", func() {
+					manifest := cfTypes.AppManifest{
+						Name:               "app-with-lifecycle",
+						AppManifestProcess: cfTypes.AppManifestProcess{Lifecycle: "docker"},
+						Processes: &cfTypes.AppManifestProcesses{
+							{Type: cfTypes.WebAppProcessType},
+							{Type: cfTypes.WorkerAppProcessType, Lifecycle: "buildpack"},
+						},
+					}
+					app, err := parseCFApp("space", manifest)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(app.Processes).To(HaveLen(2))
+					Expect(app.Processes[0].Type).To(Equal(Web))
+					Expect(app.Processes[0].Lifecycle).To(Equal(DockerLifecycleType))
+					Expect(app.Processes[1].Type).To(Equal(Worker))
+					Expect(app.Processes[1].Lifecycle).To(Equal(BuildPackLifecycleType))
+				})
 				It("validates the discovery data of an app with random route and path", func() {
 					expected := Application{
 						Metadata: Metadata{Name: "hello-spring-cloud"},
@@ -1177,7 +1207,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "hello-spring-cloud", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1208,7 +1238,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "pong-matcher-sails", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1245,7 +1275,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						Path: ".",
 					}
 					processManifestPath := filepath.Join("test_data", "rails-sample-app", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1282,7 +1312,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "app-features", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1323,7 +1353,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "sidecar-dependant-app", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1367,9 +1397,6 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 							},
 						},
 						Services: Services{
-							{
-								Name: "mysql",
-							},
 							{
 								Name:       "gateway",
 								Parameters: map[string]any{"routes": map[string]any{"path": "/music/**"}},
@@ -1378,10 +1405,13 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 								Name:        "lb",
 								BindingName: "load_balancer",
 							},
+							{
+								Name: "mysql",
+							},
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "spring-music", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1436,7 +1466,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "multiple-processes", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1472,7 +1502,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "multiple-web-processes", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1528,7 +1558,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "worker-inline-and-web-processes", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1554,22 +1584,16 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 						Routes: RouteSpec{
 							Routes: Routes{
-								{Route: "route.example.com"},
 								{Route: "another-route.example.com",
 									Protocol: HTTP2RouteProtocol,
 									Options: RouteOptions{
 										LoadBalancing: LeastConnectionLoadBalancingType,
 									},
 								},
+								{Route: "route.example.com"},
 							},
 						},
 						Services: Services{
-							{
-								Name: "my-service1",
-							},
-							{
-								Name: "my-service2",
-							},
 							{
 								Name: "my-service-with-arbitrary-params",
 								Parameters: map[string]interface{}{
@@ -1578,6 +1602,12 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 								},
 								BindingName: "my-service3",
 							},
+							{
+								Name: "my-service1",
+							},
+							{
+								Name: "my-service2",
+							},
 						},
 						Stack: "cflinuxfs3",
 						Processes: Processes{
@@ -1639,7 +1669,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 						},
 					}
 					processManifestPath := filepath.Join("test_data", "complete-manifest", "manifest.yml")
-					app, err := provider.discoverFromManifestFile(processManifestPath)
+					app, _, err := provider.discoverFromManifestFile(processManifestPath)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(app).To(BeEquivalentTo(&expected))
 				})
@@ -1665,8 +1695,7 @@ var _ = Describe("CloudFoundry Provider", Ordered, func() {
 				apps, err := provider.Discover(input)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(apps).ToNot(BeNil())
-				var resultApp Application
-				err = MapToStruct(apps.Content, &resultApp)
+				resultApp, err := documentSpec(apps.Content)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(resultApp).ToNot(Equal(Application{}))
 				Expect(resultApp.Metadata).ToNot(Equal(Metadata{}))
@@ -1897,7 +1926,7 @@ applications: []`
 				provider := &CloudFoundryProvider{
 					logger: &nopLogger,
 				}
-				app, err := provider.discoverFromManifestFile(filepath.Join("test_data", "basic-app", "manifest.yml"))
+				app, _, err := provider.discoverFromManifestFile(filepath.Join("test_data", "basic-app", "manifest.yml"))
 				Expect(err).To(BeNil())
 				out, err := yaml.Marshal(app)
 				Expect(err).NotTo(HaveOccurred())
@@ -2510,7 +2539,7 @@ var _ = Describe("Organization Name Filtering", func() {
 					for i, svc := range *services {
 						serviceNames[i] = svc.Name
 					}
-					Expect(serviceNames).To(ConsistOf("my-postgres-db", "my-redis-cache", "my-redis-sessions"))
+					Expect(serviceNames).To(Equal([]string{"my-postgres-db", "my-redis-cache", "my-redis-sessions"}))
 				})
 
 				It("should handle services without credentials", func() {
@@ -2648,6 +2677,69 @@ var _ = Describe("Organization Name Filtering", func() {
 				})
 			})
 		})
+
+		Describe("getServiceLabels", func() {
+			It("returns the service offering of each instance", func() {
+				env := map[string]json.RawMessage{
+					"VCAP_SERVICES": json.RawMessage(`{
+						"p.config-server": [{"instance_name": "config", "label": "p.config-server"}],
+						"p.mysql": [{"instance_name": "orders-db"}, {"instance_name": "users-db"}]
+					}`),
+				}
+				Expect(getServiceLabels(env)).To(Equal(map[string]string{
+					"config":    "p.config-server",
+					"orders-db": "p.mysql",
+					"users-db":  "p.mysql",
+				}))
+			})
+
+			It("returns no labels without VCAP_SERVICES", func() {
+				Expect(getServiceLabels(map[string]json.RawMessage{})).To(BeEmpty())
+			})
+		})
 	})
 
 })
+
+var _ = Describe("Canonical order of the discovered lists", func() {
+	It("sorts services, processes, routes and sidecars regardless of the manifest order", func() {
+		cfApp := cfTypes.AppManifest{
+			Name: "app",
+			Routes: &cfTypes.AppManifestRoutes{
+				{Route: "zeta.example.com"},
+				{Route: "alpha.example.com"},
+			},
+			Services: &cfTypes.AppManifestServices{
+				{Name: "redis"},
+				{Name: "mysql", BindingName: "replica"},
+				{Name: "mysql", BindingName: "primary"},
+			},
+			Processes: &cfTypes.AppManifestProcesses{
+				{Type: cfTypes.WorkerAppProcessType},
+				{Type: cfTypes.WebAppProcessType},
+			},
+			Sidecars: &cfTypes.AppManifestSideCars{
+				{Name: "proxy", Command: "./proxy", ProcessTypes: []cfTypes.AppProcessType{cfTypes.WorkerAppProcessType, cfTypes.WebAppProcessType}},
+				{Name: "authenticator", Command: "./authenticator", ProcessTypes: []cfTypes.AppProcessType{cfTypes.WebAppProcessType}},
+			},
+		}
+		app, err := parseCFApp("space", cfApp)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(app.Routes.Routes).To(HaveLen(2))
+		Expect(app.Routes.Routes[0].Route).To(Equal("alpha.example.com"))
+		Expect(app.Routes.Routes[1].Route).To(Equal("zeta.example.com"))
+		Expect(app.Services).To(Equal(Services{
+			{Name: "mysql", BindingName: "primary"},
+			{Name: "mysql", BindingName: "replica"},
+			{Name: "redis"},
+		}))
+		Expect(app.Processes).To(HaveLen(2))
+		Expect(app.Processes[0].Type).To(Equal(Web))
+		Expect(app.Processes[1].Type).To(Equal(Worker))
+		Expect(app.Sidecars).To(HaveLen(2))
+		Expect(app.Sidecars[0].Name).To(Equal("authenticator"))
+		Expect(app.Sidecars[1].Name).To(Equal("proxy"))
+		Expect(app.Sidecars[1].ProcessTypes).To(Equal([]ProcessType{Web, Worker}))
+	})
+})
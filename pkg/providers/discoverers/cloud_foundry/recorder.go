@@ -0,0 +1,346 @@
+package cloud_foundry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/go-cfclient/v3/config"
+)
+
+const (
+	// redactedValue replaces the sensitive values found in the recorded responses.
+	redactedValue = "REDACTED"
+	// oauthTokenPath is the UAA endpoint used to exchange credentials for tokens. Requests to this endpoint are never
+	// recorded and are answered with a synthetic token when replaying.
+	oauthTokenPath = "/oauth/token"
+	// replayAPIEndpoint is used as API endpoint when the recording does not contain one.
+	replayAPIEndpoint = "https://api.replay.invalid"
+)
+
+// DefaultRedactedKeys contains the JSON keys whose values are replaced by the recording transport before storing a
+// response body. The match is case insensitive and applies at any depth of the JSON document. The
+// `environment_variables` of the application environment are redacted since they commonly contain secrets.
+var DefaultRedactedKeys = []string{
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"password",
+	"client_secret",
+	"credentials",
+	"environment_variables",
+}
+
+// Recording captures the HTTP interactions between go-cfclient and the Cloud Controller API during a discovery run.
+// It can be stored as a JSON archive (gzip compressed when the file name ends with `.gz`) and served back offline
+// with a ReplayTransport.
+type Recording struct {
+	// APIEndpoint captures the scheme and host of the Cloud Controller API the recording was taken from.
+	APIEndpoint string `json:"apiEndpoint,omitempty"`
+	// RecordedAt captures the time when the first interaction was recorded.
+	RecordedAt time.Time `json:"recordedAt,omitempty"`
+	// Interactions contains the request and response pairs in the order they were performed.
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction represents a single request and response exchanged with the Cloud Controller API.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest captures the fields used to match a request when replaying. The host is not captured so that the
+// archive can be served regardless of the foundation's URL.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+}
+
+// RecordedResponse captures the status code, content type and redacted body of a response.
+type RecordedResponse struct {
+	StatusCode  int    `json:"statusCode"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+// RecordingTransport is an http.RoundTripper that forwards the requests to the underlying transport and records
+// each interaction, with the sensitive values redacted, into a Recording.
+type RecordingTransport struct {
+	// Base is the transport used to perform the requests. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+	// RedactKeys contains the JSON keys whose values are redacted. Defaults to DefaultRedactedKeys.
+	RedactKeys []string
+
+	mu        sync.Mutex
+	recording Recording
+}
+
+// NewRecordingTransport returns a RecordingTransport that uses base to perform the requests.
+func NewRecordingTransport(base http.RoundTripper) *RecordingTransport {
+	return &RecordingTransport{Base: base, RedactKeys: DefaultRedactedKeys}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil || strings.HasSuffix(req.URL.Path, oauthTokenPath) {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body for %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	keys := t.RedactKeys
+	if keys == nil {
+		keys = DefaultRedactedKeys
+	}
+	i := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  canonicalQuery(req.URL.RawQuery),
+		},
+		Response: RecordedResponse{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        redactBody(body, keys),
+		},
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.recording.Interactions) == 0 {
+		t.recording.APIEndpoint = req.URL.Scheme + "://" + req.URL.Host
+		t.recording.RecordedAt = time.Now().UTC()
+	}
+	t.recording.Interactions = append(t.recording.Interactions, i)
+	return resp, nil
+}
+
+// Recording returns a copy of the interactions recorded so far.
+func (t *RecordingTransport) Recording() *Recording {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := t.recording
+	r.Interactions = append([]Interaction(nil), t.recording.Interactions...)
+	return &r
+}
+
+// Save writes the interactions recorded so far to the given file.
+func (t *RecordingTransport) Save(path string) error {
+	return t.Recording().Save(path)
+}
+
+// Save writes the recording as JSON to the given file. The content is gzip compressed when the file name ends
+// with `.gz`.
+func (r *Recording) Save(path string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording: %w", err)
+	}
+	if strings.HasSuffix(path, ".gz") {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(b); err != nil {
+			return fmt.Errorf("failed to compress recording: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to compress recording: %w", err)
+		}
+		b = buf.Bytes()
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		return fmt.Errorf("failed to write recording to %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadRecording reads a recording previously stored with Save.
+func LoadRecording(path string) (*Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording %s: %w", path, err)
+	}
+	defer f.Close()
+	var reader io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress recording %s: %w", path, err)
+		}
+		defer zr.Close()
+		reader = zr
+	}
+	var r Recording
+	if err := json.NewDecoder(reader).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recording %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// ReplayTransport is an http.RoundTripper that serves the responses stored in a Recording without performing any
+// network call. Requests are matched by method, path and query parameters. When the same request was recorded more
+// than once, the responses are returned in the recorded order and the last one is reused afterwards.
+type ReplayTransport struct {
+	mu        sync.Mutex
+	responses map[string][]RecordedResponse
+	served    map[string]int
+}
+
+// NewReplayTransport returns a ReplayTransport that serves the interactions in the recording.
+func NewReplayTransport(r *Recording) *ReplayTransport {
+	t := &ReplayTransport{
+		responses: map[string][]RecordedResponse{},
+		served:    map[string]int{},
+	}
+	for _, i := range r.Interactions {
+		k := interactionKey(i.Request.Method, i.Request.Path, i.Request.Query)
+		t.responses[k] = append(t.responses[k], i.Response)
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if strings.HasSuffix(req.URL.Path, oauthTokenPath) {
+		return replayResponse(req, RecordedResponse{
+			StatusCode:  http.StatusOK,
+			ContentType: "application/json",
+			Body:        `{"access_token":"replay","refresh_token":"replay","token_type":"bearer","expires_in":3600}`,
+		}), nil
+	}
+	k := interactionKey(req.Method, req.URL.Path, canonicalQuery(req.URL.RawQuery))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rs, ok := t.responses[k]
+	if !ok {
+		return nil, fmt.Errorf("no recorded interaction found for %s %s", req.Method, req.URL.RequestURI())
+	}
+	idx := min(t.served[k], len(rs)-1)
+	t.served[k]++
+	return replayResponse(req, rs[idx]), nil
+}
+
+// NewReplayConfig returns a go-cfclient configuration that serves the recording offline. Additional options are
+// applied after the replay ones.
+func NewReplayConfig(r *Recording, options ...config.Option) (*config.Config, error) {
+	endpoint := r.APIEndpoint
+	if endpoint == "" {
+		endpoint = replayAPIEndpoint
+	}
+	opts := append([]config.Option{
+		config.Token("", "replay"),
+		config.HttpClient(&http.Client{Transport: NewReplayTransport(r)}),
+	}, options...)
+	return config.New(endpoint, opts...)
+}
+
+func replayResponse(req *http.Request, r RecordedResponse) *http.Response {
+	h := http.Header{}
+	if r.ContentType != "" {
+		h.Set("Content-Type", r.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+func interactionKey(method, path, query string) string {
+	return method + " " + path + "?" + query
+}
+
+// canonicalQuery sorts the query parameters so that requests with the same parameters in different order match.
+func canonicalQuery(rawQuery string) string {
+	v, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	return v.Encode()
+}
+
+// redactBody replaces the values of the given keys in a JSON body. Bodies that are not JSON are returned unchanged.
+func redactBody(body []byte, keys []string) string {
+	var doc any
+	if len(body) == 0 || json.Unmarshal(body, &doc) != nil {
+		return string(body)
+	}
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = struct{}{}
+	}
+	b, err := json.Marshal(redactValue(doc, set))
+	if err != nil {
+		return string(body)
+	}
+	return string(b)
+}
+
+func redactValue(v any, keys map[string]struct{}) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if _, ok := keys[strings.ToLower(k)]; ok {
+				t[k] = redactAll(val)
+				continue
+			}
+			t[k] = redactValue(val, keys)
+		}
+	case []any:
+		for i, val := range t {
+			t[i] = redactValue(val, keys)
+		}
+	}
+	return v
+}
+
+// redactAll replaces every scalar value in v while keeping the structure of maps and slices and the type of the
+// values, so that the redacted document can still be decoded into the same types: the strings are replaced by
+// redactedValue, and the numbers and booleans by their zero value.
+func redactAll(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			t[k] = redactAll(val)
+		}
+		return t
+	case []any:
+		for i, val := range t {
+			t[i] = redactAll(val)
+		}
+		return t
+	case float64:
+		return float64(0)
+	case bool:
+		return false
+	case nil:
+		return nil
+	}
+	return redactedValue
+}
@@ -0,0 +1,132 @@
+package cloud_foundry
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudfoundry/go-cfclient/v3/config"
+	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	"github.com/go-logr/logr"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recording and replaying a live discovery", func() {
+	var logger = logr.New(logr.Discard().GetSink())
+
+	AfterEach(func() {
+		testutil.Teardown()
+	})
+
+	DescribeTable("replays a recorded discovery offline", func(archiveName string) {
+		app := cfTypes.AppManifest{
+			Name:     "recorded-app",
+			Metadata: &cfTypes.AppMetadata{},
+			Env:      map[string]string{"FOO": "bar"},
+			Routes: &cfTypes.AppManifestRoutes{
+				{Route: "recorded-app.example.com", Protocol: cfTypes.HTTP1},
+			},
+			Sidecars: &cfTypes.AppManifestSideCars{
+				{Name: "sidecar", ProcessTypes: []cfTypes.AppProcessType{cfTypes.WebAppProcessType}, Command: "/bin/sleep", Memory: "100"},
+			},
+		}
+		m, serverURL := newMockApplication(app, GlobalT)
+		ref := AppReference{OrgName: m.organization().Name, SpaceName: m.space().Name, AppName: m.application().Name}
+
+		By("discovering the application through the recording transport")
+		rt := NewRecordingTransport(nil)
+		cfg, err := config.New(serverURL, config.Token("", "fake-refresh-token"), config.HttpClient(&http.Client{Transport: rt}))
+		Expect(err).NotTo(HaveOccurred())
+		p, err := New(&Config{CloudFoundryConfig: cfg}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		live, err := p.Discover(ref)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("storing the recording")
+		archive := filepath.Join(GinkgoT().TempDir(), archiveName)
+		Expect(rt.Save(archive)).To(Succeed())
+		testutil.Teardown()
+
+		By("replaying the recording without the API server")
+		rec, err := LoadRecording(archive)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rec.APIEndpoint).To(Equal(serverURL))
+		replayCfg, err := NewReplayConfig(rec)
+		Expect(err).NotTo(HaveOccurred())
+		p, err = New(&Config{CloudFoundryConfig: replayCfg}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		replayed, err := p.Discover(ref)
+		Expect(err).NotTo(HaveOccurred())
+		liveDoc, err := ConvertDocument(live.Content)
+		Expect(err).NotTo(HaveOccurred())
+		replayedDoc, err := ConvertDocument(replayed.Content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replayedDoc.Spec.Env).To(HaveKeyWithValue("FOO", redactedValue))
+		replayedDoc.Spec.Env = liveDoc.Spec.Env
+		replayedDoc.Metadata.DiscoveredAt = liveDoc.Metadata.DiscoveredAt
+		Expect(replayedDoc).To(Equal(liveDoc))
+		Expect(replayed.Secret).To(Equal(live.Secret))
+		Expect(replayed.Warnings).To(Equal(live.Warnings))
+	},
+		Entry("with a plain JSON archive", "recording.json"),
+		Entry("with a gzip compressed archive", "recording.json.gz"),
+	)
+
+	It("redacts the service credentials and never records the token exchange", func() {
+		app := cfTypes.AppManifest{
+			Name:     "app-with-credentials",
+			Metadata: &cfTypes.AppMetadata{},
+			Services: &cfTypes.AppManifestServices{
+				{Name: "db", Parameters: map[string]any{"username": "admin", "password": "P@ssW0rdTEST"}},
+			},
+		}
+		m, serverURL := newMockApplication(app, GlobalT)
+		rt := NewRecordingTransport(nil)
+		cfg, err := config.New(serverURL, config.Token("", "fake-refresh-token"), config.HttpClient(&http.Client{Transport: rt}))
+		Expect(err).NotTo(HaveOccurred())
+		p, err := New(&Config{CloudFoundryConfig: cfg}, &logger, false)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = p.Discover(AppReference{OrgName: m.organization().Name, SpaceName: m.space().Name, AppName: m.application().Name})
+		Expect(err).NotTo(HaveOccurred())
+
+		archive := filepath.Join(GinkgoT().TempDir(), "recording.json")
+		Expect(rt.Save(archive)).To(Succeed())
+		b, err := os.ReadFile(archive)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).NotTo(ContainSubstring("P@ssW0rdTEST"))
+		Expect(string(b)).To(ContainSubstring(redactedValue))
+		for _, i := range rt.Recording().Interactions {
+			Expect(strings.HasSuffix(i.Request.Path, oauthTokenPath)).To(BeFalse())
+		}
+	})
+
+	It("redacts the environment variables and keeps the types of the redacted values", func() {
+		body := []byte(`{"environment_variables":{"DB_URL":"postgres://admin:s3cr3t@db","PORT":8080,"DEBUG":true},"staging_env_json":{"LOG_LEVEL":"info"}}`)
+		Expect(redactBody(body, DefaultRedactedKeys)).To(MatchJSON(`{"environment_variables":{"DB_URL":"REDACTED","PORT":0,"DEBUG":false},"staging_env_json":{"LOG_LEVEL":"info"}}`))
+	})
+
+	It("fails when the request was not recorded", func() {
+		t := NewReplayTransport(&Recording{})
+		req, err := http.NewRequest(http.MethodGet, "https://api.example.com/v3/apps?names=foo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = t.RoundTrip(req)
+		Expect(err).To(MatchError("no recorded interaction found for GET /v3/apps?names=foo"))
+	})
+
+	It("matches requests regardless of the order of the query parameters", func() {
+		t := NewReplayTransport(&Recording{Interactions: []Interaction{
+			{
+				Request:  RecordedRequest{Method: http.MethodGet, Path: "/v3/apps", Query: "names=foo&space_guids=bar"},
+				Response: RecordedResponse{StatusCode: http.StatusOK, Body: "{}"},
+			},
+		}})
+		req, err := http.NewRequest(http.MethodGet, "https://api.example.com/v3/apps?space_guids=bar&names=foo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := t.RoundTrip(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,129 @@
+package cloud_foundry
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+)
+
+// SharedRoute is a route that sends the traffic to several applications or process types, e.g. the route of a
+// blue/green deployment. Generators should expose it as a single logical service instead of one per application.
+type SharedRoute struct {
+	// Route is the URL of the route.
+	Route string `yaml:"route" json:"route"`
+	// Apps contains the names of the applications that receive the traffic of the route, sorted by name.
+	Apps []string `yaml:"apps" json:"apps"`
+	// Destinations contains the destinations of the route.
+	Destinations []RouteDestination `yaml:"destinations" json:"destinations"`
+}
+
+// SharedRoutes returns the routes listed with the applications that send the traffic to more than one application or
+// process type, sorted by route. It requires MapRoutes, is empty until ListApps is called and only contains the routes
+// of the last call.
+func (c *CloudFoundryProvider) SharedRoutes() []SharedRoute {
+	var shared []SharedRoute
+	for route, destinations := range c.routeMap {
+		if !isSharedRoute(destinations) {
+			continue
+		}
+		var apps []string
+		for _, d := range destinations {
+			if !slices.Contains(apps, d.App) {
+				apps = append(apps, d.App)
+			}
+		}
+		sort.Strings(apps)
+		shared = append(shared, SharedRoute{Route: route, Apps: apps, Destinations: destinations})
+	}
+	sort.Slice(shared, func(i, j int) bool { return shared[i].Route < shared[j].Route })
+	return shared
+}
+
+// isSharedRoute checks if the route sends the traffic to more than one application or process type.
+func isSharedRoute(destinations []RouteDestination) bool {
+	targets := map[string]bool{}
+	for _, d := range destinations {
+		targets[d.App+"/"+string(d.ProcessType)] = true
+	}
+	return len(targets) > 1
+}
+
+// mapSpaceRoutes adds the routes of the space, with their destinations, to the route map.
+func (c *CloudFoundryProvider) mapSpaceRoutes(space *resource.Space) error {
+	opts := client.NewRouteListOptions()
+	opts.SpaceGUIDs.EqualTo(space.GUID)
+	routes, err := c.cli.Routes.ListAll(context.Background(), opts)
+	if err != nil {
+		return fmt.Errorf("error listing the routes of space %s: %w", space.Name, err)
+	}
+	for _, r := range routes {
+		destinations := make([]RouteDestination, 0, len(r.Destinations))
+		for _, d := range r.Destinations {
+			dest, err := c.routeDestination(d)
+			if err != nil {
+				c.logger.Info("Keeping the GUID of the application of a route destination", "route", r.URL, "error", err.Error())
+			}
+			destinations = append(destinations, dest)
+		}
+		c.routeMap[r.URL] = destinations
+	}
+	return nil
+}
+
+// routeDestination converts the destination of a route, resolving the name of its application. When the name can't
+// be retrieved, e.g. for an application of a space the user can't read, the destination keeps the GUID of the
+// application and the error is returned along with it.
+func (c *CloudFoundryProvider) routeDestination(d resource.RouteDestination) (RouteDestination, error) {
+	dest := RouteDestination{ProcessType: Web}
+	var err error
+	if d.App.GUID != nil {
+		dest.App, err = c.appName(*d.App.GUID)
+		if err != nil {
+			dest.App = *d.App.GUID
+		}
+	}
+	if d.App.Process != nil && d.App.Process.Type != "" {
+		dest.ProcessType = ProcessType(d.App.Process.Type)
+	}
+	if d.Port != nil {
+		dest.Port = *d.Port
+	}
+	if d.Weight != nil {
+		dest.Weight = *d.Weight
+	}
+	if d.Protocol != nil {
+		dest.Protocol = RouteProtocol(*d.Protocol)
+	}
+	return dest, err
+}
+
+// appName returns the name of the application, retrieving it when the application was not listed nor discovered.
+func (c *CloudFoundryProvider) appName(guid string) (string, error) {
+	if name, ok := c.appNames[guid]; ok {
+		return name, nil
+	}
+	app, err := c.cli.Applications.Get(context.Background(), guid)
+	if err != nil {
+		return "", fmt.Errorf("error getting application %s: %w", guid, err)
+	}
+	c.appNames[guid] = app.Name
+	return app.Name, nil
+}
+
+// destinationProtocol returns the protocol of the route for the application: the protocol of its first destination,
+// or of the first destination of the route when none sends the traffic to the application.
+func destinationProtocol(appName string, destinations []RouteDestination) RouteProtocol {
+	for _, d := range destinations {
+		if d.App == appName {
+			return d.Protocol
+		}
+	}
+	if len(destinations) > 0 {
+		return destinations[0].Protocol
+	}
+	return ""
+}
@@ -0,0 +1,215 @@
+package cloud_foundry
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudfoundry/go-cfclient/v3/config"
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	"github.com/go-logr/logr"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Live route discovery", func() {
+	var logger = logr.New(logr.Discard().GetSink())
+
+	discover := func(app cfTypes.AppManifest, opts ...mockOption) Application {
+		discovered, result := discoverLiveApplication(GlobalT, Config{}, false, app, opts...)
+		Expect(result.Warnings).To(BeEmpty())
+		return discovered
+	}
+
+	AfterEach(func() {
+		testutil.Teardown()
+	})
+
+	It("captures the route services, the destinations and the hash load balancing options", func() {
+		app := discover(cfTypes.AppManifest{
+			Name:     "app",
+			Metadata: &cfTypes.AppMetadata{},
+			Routes: &cfTypes.AppManifestRoutes{
+				{Route: "app.example.com", Options: &cfTypes.AppRouteOptions{LoadBalancing: "hash", HashHeader: "X-Tenant-Id", HashBalance: "1.25"}},
+				{Route: "app.internal.example.com", Protocol: cfTypes.HTTP2},
+			},
+		},
+			withRouteService("app.example.com", "https://proxy.example.com"),
+			withDestinations("app.example.com",
+				RouteDestination{App: "app", ProcessType: Web, Port: 8080, Weight: 80, Protocol: HTTPRouteProtocol},
+				RouteDestination{App: "app", ProcessType: "admin", Port: 9090, Weight: 20, Protocol: HTTPRouteProtocol},
+			),
+		)
+		Expect(app.Routes.Routes).To(Equal(Routes{
+			{
+				Route:           "app.example.com",
+				Protocol:        HTTPRouteProtocol,
+				Options:         RouteOptions{LoadBalancing: HashLoadBalancingType, HashHeader: "X-Tenant-Id", HashBalance: "1.25"},
+				RouteServiceURL: "https://proxy.example.com",
+				Destinations: []RouteDestination{
+					{App: "app", ProcessType: Web, Port: 8080, Weight: 80, Protocol: HTTPRouteProtocol},
+					{App: "app", ProcessType: "admin", Port: 9090, Weight: 20, Protocol: HTTPRouteProtocol},
+				},
+				Shared: true,
+			},
+			{
+				Route:        "app.internal.example.com",
+				Protocol:     HTTP2RouteProtocol,
+				Destinations: []RouteDestination{{App: "app", ProcessType: Web, Protocol: HTTP2RouteProtocol}},
+			},
+		}))
+	})
+
+	It("captures the destinations of the other applications of a blue/green route", func() {
+		app := discover(cfTypes.AppManifest{
+			Name:     "app-blue",
+			Metadata: &cfTypes.AppMetadata{},
+			Routes:   &cfTypes.AppManifestRoutes{{Route: "app.example.com"}},
+		},
+			withDestinations("app.example.com",
+				RouteDestination{App: "app-green", ProcessType: Web, Port: 8080, Weight: 90, Protocol: HTTP2RouteProtocol},
+				RouteDestination{App: "app-blue", ProcessType: Web, Port: 8080, Weight: 10, Protocol: HTTPRouteProtocol},
+			),
+		)
+		Expect(app.Routes.Routes).To(HaveLen(1))
+		By("taking the protocol of the destination of the discovered application")
+		Expect(app.Routes.Routes[0].Protocol).To(Equal(HTTPRouteProtocol))
+		Expect(app.Routes.Routes[0].Destinations).To(Equal([]RouteDestination{
+			{App: "app-green", ProcessType: Web, Port: 8080, Weight: 90, Protocol: HTTP2RouteProtocol},
+			{App: "app-blue", ProcessType: Web, Port: 8080, Weight: 10, Protocol: HTTPRouteProtocol},
+		}))
+		Expect(app.Routes.Routes[0].Shared).To(BeTrue())
+	})
+
+	It("keeps the GUID of the applications that can't be retrieved and reports them", func() {
+		app, result := discoverLiveApplication(GlobalT, Config{}, false, cfTypes.AppManifest{
+			Name:     "app-blue",
+			Metadata: &cfTypes.AppMetadata{},
+			Routes:   &cfTypes.AppManifestRoutes{{Route: "app.example.com"}},
+		},
+			withDestinations("app.example.com",
+				RouteDestination{App: "app-green", ProcessType: Web, Port: 8080, Protocol: HTTPRouteProtocol},
+				RouteDestination{App: "app-blue", ProcessType: Web, Port: 8080, Protocol: HTTPRouteProtocol},
+			),
+			withUnreadableApp("app-green"),
+		)
+		Expect(result.Warnings).To(HaveLen(1))
+		Expect(result.Warnings[0].Path).To(Equal("routes.routes[app.example.com].destinations"))
+		Expect(result.Warnings[0].Message).To(ContainSubstring("the name of the application of the destination is not known"))
+		Expect(app.Routes.Routes[0].Destinations).To(HaveLen(2))
+		Expect(app.Routes.Routes[0].Destinations[0].App).To(Equal(result.Warnings[0].Value))
+		Expect(app.Routes.Routes[0].Destinations[1].App).To(Equal("app-blue"))
+	})
+
+	It("keeps the options of the routes without hash load balancing", func() {
+		app := discover(cfTypes.AppManifest{
+			Name:     "app",
+			Metadata: &cfTypes.AppMetadata{},
+			Routes: &cfTypes.AppManifestRoutes{
+				{Route: "app.example.com", Options: &cfTypes.AppRouteOptions{LoadBalancing: "least-connection"}},
+			},
+		})
+		Expect(app.Routes.Routes).To(HaveLen(1))
+		Expect(app.Routes.Routes[0].Options).To(Equal(RouteOptions{LoadBalancing: LeastConnectionLoadBalancingType}))
+		Expect(app.Routes.Routes[0].RouteServiceURL).To(BeEmpty())
+	})
+
+	Context("mapping the routes of the foundation", func() {
+		var (
+			g         = testutil.NewObjectJSONGenerator()
+			org       = g.Organization()
+			space     = g.Space()
+			blue      = resource.App{Name: "app-blue", Resource: resource.Resource{GUID: testutil.RandomGUID()}}
+			green     = resource.App{Name: "app-green", Resource: resource.Resource{GUID: testutil.RandomGUID()}}
+			worker    = resource.App{Name: "worker", Resource: resource.Resource{GUID: testutil.RandomGUID()}}
+			serverURL string
+		)
+
+		destination := func(app resource.App, process string, weight int) resource.RouteDestination {
+			return resource.RouteDestination{
+				App:      resource.RouteDestinationApp{GUID: &app.GUID, Process: &resource.RouteDestinationAppProcess{Type: process}},
+				Weight:   &weight,
+				Port:     ptrTo(8080),
+				Protocol: ptrTo("http1"),
+			}
+		}
+
+		BeforeEach(func() {
+			routes := []string{
+				toJSON(resource.Route{URL: "app.example.com", Destinations: []resource.RouteDestination{
+					destination(blue, "web", 50), destination(green, "web", 50),
+				}}),
+				toJSON(resource.Route{URL: "worker.example.com", Destinations: []resource.RouteDestination{
+					destination(worker, "web", 0),
+				}}),
+				toJSON(resource.Route{URL: "api.example.com", Destinations: []resource.RouteDestination{
+					destination(worker, "web", 0), destination(worker, "api", 0),
+				}}),
+			}
+			spaceRes := resource.Space{}
+			Expect(json.Unmarshal([]byte(space.JSON), &spaceRes)).To(Succeed())
+			spaceRes.Relationships.Organization.Data = &resource.Relationship{GUID: org.GUID}
+			space.JSON = toJSON(spaceRes)
+			serverURL = testutil.SetupMultiple([]testutil.MockRoute{
+				{
+					Method:      http.MethodGet,
+					Endpoint:    "/v3/organizations",
+					Output:      g.Paged([]string{org.JSON}),
+					Status:      http.StatusOK,
+					QueryString: "names=" + org.Name + "&" + pagingQueryString,
+				},
+				{
+					Method:      http.MethodGet,
+					Endpoint:    "/v3/spaces",
+					Output:      g.Paged([]string{space.JSON}),
+					Status:      http.StatusOK,
+					QueryString: "names=" + space.Name + "&organization_guids=" + org.GUID + "&" + pagingQueryString,
+				},
+				{
+					Method:      http.MethodGet,
+					Endpoint:    "/v3/apps",
+					Output:      g.Paged([]string{toJSON(blue), toJSON(green), toJSON(worker)}),
+					Status:      http.StatusOK,
+					QueryString: "organization_guids=" + org.GUID + "&" + pagingQueryString + "&space_guids=" + space.GUID,
+				},
+				{
+					Method:      http.MethodGet,
+					Endpoint:    "/v3/routes",
+					Output:      g.Paged(routes),
+					Status:      http.StatusOK,
+					QueryString: pagingQueryString + "&space_guids=" + space.GUID,
+				},
+			}, GlobalT)
+		})
+
+		It("reports the routes shared by several applications or process types", func() {
+			cfg, err := config.New(serverURL, config.Token("", "fake-refresh-token"), config.SkipTLSValidation())
+			Expect(err).NotTo(HaveOccurred())
+			p, err := New(&Config{CloudFoundryConfig: cfg, OrgNames: []string{org.Name}, SpaceNames: []string{space.Name}, MapRoutes: true}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.SharedRoutes()).To(BeEmpty())
+			apps, err := p.ListApps()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apps[org.Name]).To(HaveLen(3))
+			Expect(p.SharedRoutes()).To(Equal([]SharedRoute{
+				{
+					Route: "api.example.com",
+					Apps:  []string{"worker"},
+					Destinations: []RouteDestination{
+						{App: "worker", ProcessType: Web, Port: 8080, Protocol: HTTPRouteProtocol},
+						{App: "worker", ProcessType: "api", Port: 8080, Protocol: HTTPRouteProtocol},
+					},
+				},
+				{
+					Route: "app.example.com",
+					Apps:  []string{"app-blue", "app-green"},
+					Destinations: []RouteDestination{
+						{App: "app-blue", ProcessType: Web, Port: 8080, Weight: 50, Protocol: HTTPRouteProtocol},
+						{App: "app-green", ProcessType: Web, Port: 8080, Weight: 50, Protocol: HTTPRouteProtocol},
+					},
+				},
+			}))
+		})
+	})
+})
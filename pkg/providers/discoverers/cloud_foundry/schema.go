@@ -0,0 +1,21 @@
+package cloud_foundry
+
+import (
+	"bytes"
+	_ "embed"
+)
+
+//go:generate go run github.com/konveyor/asset-generation/internal/cmd/schemagen -o schema.json
+
+// jsonSchema contains the JSON schema of the discovery manifest, generated from the Application type by `go generate`.
+//
+//go:embed schema.json
+var jsonSchema []byte
+
+// JSONSchema returns the JSON Schema (draft 7) of the discovery manifest generated for an Application. The schema is
+// generated from the Go types: the property names match the JSON encoding of the `spec` of the discovery content, the
+// descriptions are taken from the field comments and the constraints from the `validate` tags. Helm charts can ship
+// it as their `values.schema.json` to validate the discovery manifest provided as values.
+func JSONSchema() ([]byte, error) {
+	return bytes.Clone(jsonSchema), nil
+}
@@ -0,0 +1,84 @@
+package cloud_foundry
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/konveyor/asset-generation/internal/jsonschema"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+var _ = Describe("JSON schema of the discovery manifest", func() {
+	var (
+		logger = logr.New(logr.Discard().GetSink())
+		schema []byte
+	)
+
+	BeforeEach(func() {
+		var err error
+		schema, err = JSONSchema()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("describes the fields with their comments and validator constraints", func() {
+		var s jsonschema.Schema
+		Expect(json.Unmarshal(schema, &s)).To(Succeed())
+		Expect(s.Schema).To(Equal(jsonschema.Draft7))
+		Expect(s.Title).To(Equal("Application"))
+		Expect(s.Required).To(ConsistOf("name"))
+		Expect(s.Properties).To(HaveKey("buildPacks"))
+		Expect(s.Properties["name"].Description).To(Equal("Name capture the `name` field int CF application manifest"))
+
+		procs := s.Properties["processes"]
+		Expect(procs.Type).To(Equal("array"))
+		Expect(procs.Items.Required).To(ConsistOf("type"))
+		Expect(procs.Items.Properties["type"].Enum).To(Equal([]string{"web", "worker"}))
+		Expect(*procs.Items.Properties["instances"].Minimum).To(Equal(1))
+
+		hc := procs.Items.Properties["healthCheck"]
+		Expect(hc.Properties).To(HaveKey("type"))
+		Expect(*hc.Properties["timeout"].Maximum).To(Equal(180))
+		Expect(hc.Then.Required).To(ConsistOf("type"))
+
+		routes := s.Properties["routes"].Properties["routes"]
+		Expect(routes.Items.Required).To(ConsistOf("route"))
+		Expect(routes.Items.Properties["options"].Properties["loadBalancing"].Enum).To(ConsistOf("round-robin", "least-connection", "hash"))
+	})
+
+	It("is up to date with the discovery types", func() {
+		generated, err := jsonschema.Generate(reflect.TypeOf(Application{}), ".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schema).To(MatchJSON(generated), "the schema is out of date: run go generate ./pkg/providers/discoverers/cloud_foundry")
+	})
+
+	DescribeTable("validates the discovery content as Helm values", func(manifest string) {
+		p, err := New(&Config{ManifestPath: manifest}, &logger, true)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := p.Discover(AppReference{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chartutil.ValidateAgainstSingleSchema(result.Content["spec"].(map[string]any), schema)).To(Succeed())
+	},
+		Entry("with a basic application", filepath.Join("test_data", "basic-app", "manifest.yml")),
+		Entry("with a complete manifest", filepath.Join("test_data", "complete-manifest", "manifest.yml")),
+		Entry("with features", filepath.Join("test_data", "app-features", "manifest.yml")),
+		Entry("with a docker image", filepath.Join("test_data", "process_manifest", "manifest.yml")),
+		Entry("with a sidecar", filepath.Join("test_data", "sidecar-dependant-app", "manifest.yml")),
+	)
+
+	It("rejects values that do not satisfy the constraints", func() {
+		values := map[string]any{
+			"name": "app",
+			"processes": []any{
+				map[string]any{"type": "task", "instances": 0},
+			},
+		}
+		err := chartutil.ValidateAgainstSingleSchema(values, schema)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("processes.0.type"))
+		Expect(err.Error()).To(ContainSubstring("processes.0.instances"))
+	})
+})
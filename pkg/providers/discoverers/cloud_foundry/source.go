@@ -0,0 +1,358 @@
+package cloud_foundry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
+)
+
+const (
+	// procfileName is the name of the file that declares the commands of the process types of the application.
+	procfileName        = "Procfile"
+	springBootFramework = "spring-boot"
+)
+
+// sourceDetector detects the runtime of the application from a build file found in the source directory.
+type sourceDetector struct {
+	// file is the name of the build file in the root of the source directory.
+	file string
+	// detect returns the runtime described by the content of the build file.
+	detect func(data []byte) (*Runtime, error)
+}
+
+// sourceDetectors are tried in order and the first one whose build file exists detects the runtime.
+var sourceDetectors = []sourceDetector{
+	{file: "pom.xml", detect: detectMaven},
+	{file: "build.gradle", detect: detectGradle},
+	{file: "build.gradle.kts", detect: detectGradle},
+	{file: "package.json", detect: detectNode},
+	{file: "go.mod", detect: detectGo},
+	{file: "Pipfile", detect: detectPipenv},
+	{file: "requirements.txt", detect: detectPip},
+	{file: "Gemfile", detect: detectBundler},
+}
+
+// sourcePath returns the location of the application source: the `path` of the application relative to the manifest
+// file, or the directory of the manifest when the application does not define one, as the cf CLI does. The paths of
+// the applications of archive manifests were already resolved to the archive or to one of its entries.
+func sourcePath(manifestFile, appPath string) string {
+	if filepath.IsAbs(appPath) {
+		return appPath
+	}
+	if _, _, ok := splitArchivePath(appPath); ok {
+		return appPath
+	}
+	if archive, _, ok := splitArchivePath(manifestFile); ok {
+		if appPath == archive {
+			return appPath
+		}
+		manifestFile = archive
+	}
+	return filepath.Join(filepath.Dir(manifestFile), appPath)
+}
+
+// inspectSource detects the runtime of the application source, which is either a directory or a Java archive. It
+// returns nil when the source does not match any known runtime nor contains a Procfile.
+func inspectSource(source string) (*Runtime, error) {
+	if _, _, ok := splitArchivePath(source); ok {
+		return nil, fmt.Errorf("unable to inspect %s: archives nested in archives are not supported", source)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("unable to inspect the application source: %w", err)
+	}
+	if !info.IsDir() {
+		if !isZipArchive(source) {
+			return nil, fmt.Errorf("unable to inspect %s: the application source must be a directory or an archive", source)
+		}
+		return inspectJavaArchive(source)
+	}
+	var runtime *Runtime
+	for _, d := range sourceDetectors {
+		data, err := os.ReadFile(filepath.Join(source, d.file))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", d.file, err)
+		}
+		if runtime, err = d.detect(data); err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", d.file, err)
+		}
+		break
+	}
+	if runtime != nil && runtime.Language == PythonRuntimeLanguage && runtime.Version == "" {
+		// The Python buildpack reads the version from runtime.txt, e.g. `python-3.11.4`
+		if data, err := os.ReadFile(filepath.Join(source, "runtime.txt")); err == nil {
+			runtime.Version = strings.TrimPrefix(strings.TrimSpace(string(data)), "python-")
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(source, procfileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return runtime, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", procfileName, err)
+	}
+	if runtime == nil {
+		runtime = &Runtime{}
+	}
+	runtime.Procfile = parseProcfile(data)
+	return runtime, nil
+}
+
+// inspectJavaArchive detects the Java version and the Spring Boot version of a jar or war from the attributes of its
+// `META-INF/MANIFEST.MF`.
+func inspectJavaArchive(archive string) (*Runtime, error) {
+	runtime := &Runtime{Language: JavaRuntimeLanguage}
+	data, err := readArchiveEntry(archive, "META-INF/MANIFEST.MF")
+	if errors.Is(err, fs.ErrNotExist) {
+		return runtime, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	attrs := map[string]string{}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		if k, v, ok := strings.Cut(s.Text(), ":"); ok {
+			attrs[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	runtime.Version = attrs["Build-Jdk-Spec"]
+	if v, ok := attrs["Spring-Boot-Version"]; ok {
+		runtime.Framework = springBootFramework
+		runtime.FrameworkVersion = v
+	}
+	return runtime, nil
+}
+
+// pomProject is the subset of the Maven POM used to detect the runtime.
+type pomProject struct {
+	Parent struct {
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Dependencies []pomDependency `xml:"dependencies>dependency"`
+	Managed      []pomDependency `xml:"dependencyManagement>dependencies>dependency"`
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+func detectMaven(data []byte) (*Runtime, error) {
+	var pom pomProject
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, err
+	}
+	runtime := &Runtime{Language: JavaRuntimeLanguage, BuildTool: "maven"}
+	props := map[string]string{}
+	for _, p := range pom.Properties.Entries {
+		props[p.XMLName.Local] = strings.TrimSpace(p.Value)
+	}
+	for _, k := range []string{"java.version", "maven.compiler.release", "maven.compiler.source"} {
+		if v := props[k]; v != "" {
+			runtime.Version = v
+			break
+		}
+	}
+	switch {
+	case pom.Parent.ArtifactID == "spring-boot-starter-parent":
+		runtime.Framework, runtime.FrameworkVersion = springBootFramework, pom.Parent.Version
+	default:
+		for _, d := range append(pom.Managed, pom.Dependencies...) {
+			if d.GroupID == "org.springframework.boot" {
+				runtime.Framework, runtime.FrameworkVersion = springBootFramework, d.Version
+				break
+			}
+		}
+	}
+	// Resolve the versions defined as properties, e.g. `${spring-boot.version}`
+	if m := mavenProperty.FindStringSubmatch(runtime.FrameworkVersion); m != nil {
+		runtime.FrameworkVersion = props[m[1]]
+	}
+	return runtime, nil
+}
+
+var (
+	mavenProperty       = regexp.MustCompile(`^\$\{(.+)\}$`)
+	gradleSpringBoot    = regexp.MustCompile(`id\s*\(?\s*["']org\.springframework\.boot["']\s*\)?\s+version\s+["']([^"']+)["']`)
+	gradleToolchain     = regexp.MustCompile(`JavaLanguageVersion\.of\(\s*(\d+)\s*\)`)
+	gradleCompatibility = regexp.MustCompile(`sourceCompatibility\s*=\s*(?:JavaVersion\.VERSION_|["'])?([\d._]+)`)
+)
+
+func detectGradle(data []byte) (*Runtime, error) {
+	runtime := &Runtime{Language: JavaRuntimeLanguage, BuildTool: "gradle"}
+	if m := gradleToolchain.FindSubmatch(data); m != nil {
+		runtime.Version = string(m[1])
+	} else if m := gradleCompatibility.FindSubmatch(data); m != nil {
+		runtime.Version = strings.ReplaceAll(string(m[1]), "_", ".")
+	}
+	if m := gradleSpringBoot.FindSubmatch(data); m != nil {
+		runtime.Framework, runtime.FrameworkVersion = springBootFramework, string(m[1])
+	}
+	return runtime, nil
+}
+
+// nodeFrameworks are the packages that identify the framework of a Node.js application, in order of precedence.
+var nodeFrameworks = []struct{ pkg, name string }{
+	{"next", "next"},
+	{"@nestjs/core", "nestjs"},
+	{"express", "express"},
+}
+
+func detectNode(data []byte) (*Runtime, error) {
+	var pkg struct {
+		Engines      map[string]string `json:"engines"`
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	runtime := &Runtime{Language: NodeRuntimeLanguage, BuildTool: "npm", Version: pkg.Engines["node"]}
+	for _, f := range nodeFrameworks {
+		if v, ok := pkg.Dependencies[f.pkg]; ok {
+			runtime.Framework, runtime.FrameworkVersion = f.name, v
+			break
+		}
+	}
+	return runtime, nil
+}
+
+var goDirective = regexp.MustCompile(`(?m)^go\s+(\S+)`)
+
+func detectGo(data []byte) (*Runtime, error) {
+	runtime := &Runtime{Language: GoRuntimeLanguage, BuildTool: "go"}
+	if m := goDirective.FindSubmatch(data); m != nil {
+		runtime.Version = string(m[1])
+	}
+	return runtime, nil
+}
+
+// pythonFrameworks are the packages that identify the framework of a Python application, in order of precedence.
+var pythonFrameworks = []string{"django", "flask", "fastapi"}
+
+var (
+	pipRequirement      = regexp.MustCompile(`(?m)^\s*([A-Za-z0-9_.\-]+)\s*(?:\[[^\]]*\])?\s*(?:[=~<>!]=?\s*([^\s,;#]+))?`)
+	pipenvPythonVersion = regexp.MustCompile(`(?m)^\s*python_(?:full_)?version\s*=\s*["']([^"']+)["']`)
+	pipenvPackage       = regexp.MustCompile(`(?m)^\s*["']?([A-Za-z0-9_.\-]+)["']?\s*=\s*["']([^"']*)["']`)
+)
+
+func detectPip(data []byte) (*Runtime, error) {
+	runtime := &Runtime{Language: PythonRuntimeLanguage, BuildTool: "pip"}
+	packages := map[string]string{}
+	for _, m := range pipRequirement.FindAllSubmatch(data, -1) {
+		packages[strings.ToLower(string(m[1]))] = string(m[2])
+	}
+	setPythonFramework(runtime, packages)
+	return runtime, nil
+}
+
+func detectPipenv(data []byte) (*Runtime, error) {
+	runtime := &Runtime{Language: PythonRuntimeLanguage, BuildTool: "pipenv"}
+	if m := pipenvPythonVersion.FindSubmatch(data); m != nil {
+		runtime.Version = string(m[1])
+	}
+	packages := map[string]string{}
+	for _, m := range pipenvPackage.FindAllSubmatch(data, -1) {
+		v := string(m[2])
+		if v == "*" {
+			v = ""
+		}
+		packages[strings.ToLower(string(m[1]))] = strings.TrimLeft(v, "=~<>! ")
+	}
+	setPythonFramework(runtime, packages)
+	return runtime, nil
+}
+
+func setPythonFramework(runtime *Runtime, packages map[string]string) {
+	for _, f := range pythonFrameworks {
+		if v, ok := packages[f]; ok {
+			runtime.Framework, runtime.FrameworkVersion = f, v
+			return
+		}
+	}
+}
+
+var (
+	gemfileRuby  = regexp.MustCompile(`(?m)^\s*ruby\s+["']([^"']+)["']`)
+	gemfileRails = regexp.MustCompile(`(?m)^\s*gem\s+["']rails["'](?:\s*,\s*["']([^"']+)["'])?`)
+)
+
+func detectBundler(data []byte) (*Runtime, error) {
+	runtime := &Runtime{Language: RubyRuntimeLanguage, BuildTool: "bundler"}
+	if m := gemfileRuby.FindSubmatch(data); m != nil {
+		runtime.Version = string(m[1])
+	}
+	if m := gemfileRails.FindSubmatch(data); m != nil {
+		runtime.Framework, runtime.FrameworkVersion = "rails", strings.TrimLeft(string(m[1]), "=~<>! ")
+	}
+	return runtime, nil
+}
+
+// parseProcfile returns the commands of the process types declared in the Procfile, one `<type>: <command>` per line.
+func parseProcfile(data []byte) map[string]string {
+	commands := map[string]string{}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if t, cmd, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(t) != "" {
+			commands[strings.TrimSpace(t)] = strings.TrimSpace(cmd)
+		}
+	}
+	return commands
+}
+
+// applyProcfile sets the command of the processes that do not define one to the command of their type in the
+// Procfile, since the buildpacks use it as the start command.
+func applyProcfile(app *Application) {
+	if app.Runtime == nil {
+		return
+	}
+	for i := range app.Processes {
+		if cmd := app.Runtime.Procfile[string(app.Processes[i].Type)]; cmd != "" && app.Processes[i].Command == "" {
+			app.Processes[i].Command = cmd
+		}
+	}
+}
+
+// inspectApplicationSource sets the runtime of the application discovered from the manifest file and the commands of
+// its Procfile. A source that can not be inspected is reported as a warning instead of failing the discovery.
+func (c *CloudFoundryProvider) inspectApplicationSource(app *Application, manifestFile string) []pTypes.Warning {
+	source := sourcePath(manifestFile, app.Path)
+	runtime, err := inspectSource(source)
+	if err != nil {
+		c.logger.Info("Unable to inspect the application source", "app_name", app.Name, "source", source, "error", err)
+		return []pTypes.Warning{{Path: "path", Value: app.Path, Message: err.Error()}}
+	}
+	if runtime == nil {
+		c.logger.Info("No known runtime found in the application source", "app_name", app.Name, "source", source)
+		return nil
+	}
+	c.logger.Info("Detected the runtime of the application source", "app_name", app.Name, "source", source, "language", runtime.Language, "framework", runtime.Framework)
+	app.Runtime = runtime
+	applyProcfile(app)
+	return nil
+}
@@ -0,0 +1,154 @@
+package cloud_foundry
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Source inspection", func() {
+	var (
+		logger = logr.New(logr.Discard().GetSink())
+		dir    string
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writeFiles := func(files map[string]string) {
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		}
+	}
+
+	DescribeTable("detects the runtime from the build files", func(files map[string]string, expected Runtime) {
+		writeFiles(files)
+		runtime, err := inspectSource(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runtime).To(Equal(&expected))
+	},
+		Entry("with a Spring Boot Maven project", map[string]string{"pom.xml": `<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.2.1</version>
+  </parent>
+  <properties>
+    <java.version>17</java.version>
+  </properties>
+</project>`}, Runtime{Language: JavaRuntimeLanguage, Version: "17", BuildTool: "maven", Framework: "spring-boot", FrameworkVersion: "3.2.1"}),
+		Entry("with a Maven project importing the Spring Boot BOM", map[string]string{"pom.xml": `<project>
+  <properties>
+    <maven.compiler.release>21</maven.compiler.release>
+    <spring-boot.version>3.3.0</spring-boot.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>org.springframework.boot</groupId>
+        <artifactId>spring-boot-dependencies</artifactId>
+        <version>${spring-boot.version}</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>`}, Runtime{Language: JavaRuntimeLanguage, Version: "21", BuildTool: "maven", Framework: "spring-boot", FrameworkVersion: "3.3.0"}),
+		Entry("with a Spring Boot Gradle project", map[string]string{"build.gradle": `plugins {
+  id 'org.springframework.boot' version '2.7.18'
+}
+java {
+  sourceCompatibility = JavaVersion.VERSION_11
+}`}, Runtime{Language: JavaRuntimeLanguage, Version: "11", BuildTool: "gradle", Framework: "spring-boot", FrameworkVersion: "2.7.18"}),
+		Entry("with a Gradle Kotlin project using a toolchain", map[string]string{"build.gradle.kts": `plugins {
+  id("org.springframework.boot") version "3.1.5"
+}
+java { toolchain { languageVersion = JavaLanguageVersion.of(17) } }`}, Runtime{Language: JavaRuntimeLanguage, Version: "17", BuildTool: "gradle", Framework: "spring-boot", FrameworkVersion: "3.1.5"}),
+		Entry("with a Node.js project", map[string]string{"package.json": `{"engines": {"node": ">=18"}, "dependencies": {"express": "^4.18.2"}}`},
+			Runtime{Language: NodeRuntimeLanguage, Version: ">=18", BuildTool: "npm", Framework: "express", FrameworkVersion: "^4.18.2"}),
+		Entry("with a pip project and runtime.txt", map[string]string{"requirements.txt": "# web\nFlask==2.3.2\ngunicorn\n", "runtime.txt": "python-3.11.4\n"},
+			Runtime{Language: PythonRuntimeLanguage, Version: "3.11.4", BuildTool: "pip", Framework: "flask", FrameworkVersion: "2.3.2"}),
+		Entry("with a Pipenv project", map[string]string{"Pipfile": "[packages]\ndjango = \"==4.2\"\n\n[requires]\npython_version = \"3.10\"\n"},
+			Runtime{Language: PythonRuntimeLanguage, Version: "3.10", BuildTool: "pipenv", Framework: "django", FrameworkVersion: "4.2"}),
+		Entry("with a Ruby project", map[string]string{"Gemfile": "source 'https://rubygems.org'\nruby '3.2.2'\ngem 'rails', '~> 7.0.4'\n"},
+			Runtime{Language: RubyRuntimeLanguage, Version: "3.2.2", BuildTool: "bundler", Framework: "rails", FrameworkVersion: "7.0.4"}),
+		Entry("with a Go module", map[string]string{"go.mod": "module example.com/app\n\ngo 1.22\n"},
+			Runtime{Language: GoRuntimeLanguage, Version: "1.22", BuildTool: "go"}),
+		Entry("with a Procfile only", map[string]string{"Procfile": "web: ./start.sh --port $PORT\n# comment\nworker: ./worker\n"},
+			Runtime{Procfile: map[string]string{"web": "./start.sh --port $PORT", "worker": "./worker"}}),
+	)
+
+	It("returns no runtime when the source does not contain known build files", func() {
+		writeFiles(map[string]string{"index.html": "<html></html>"})
+		Expect(inspectSource(dir)).To(BeNil())
+	})
+
+	It("detects the Java and Spring Boot versions of a jar", func() {
+		jar := filepath.Join(dir, "app.jar")
+		f, err := os.Create(jar)
+		Expect(err).NotTo(HaveOccurred())
+		w := zip.NewWriter(f)
+		ew, err := w.Create("META-INF/MANIFEST.MF")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ew.Write([]byte("Manifest-Version: 1.0\r\nBuild-Jdk-Spec: 17\r\nSpring-Boot-Version: 3.2.1\r\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		Expect(f.Close()).To(Succeed())
+
+		Expect(inspectSource(jar)).To(Equal(&Runtime{Language: JavaRuntimeLanguage, Version: "17", Framework: "spring-boot", FrameworkVersion: "3.2.1"}))
+	})
+
+	When("discovering an application with source inspection", func() {
+		discover := func(manifest string) (*Application, []any) {
+			p, err := New(&Config{ManifestPath: filepath.Join(dir, manifest), InspectSource: true}, &logger, false)
+			Expect(err).NotTo(HaveOccurred())
+			result, err := p.Discover(AppReference{})
+			Expect(err).NotTo(HaveOccurred())
+			app, err := documentSpec(result.Content)
+			Expect(err).NotTo(HaveOccurred())
+			warnings := make([]any, 0, len(result.Warnings))
+			for _, w := range result.Warnings {
+				warnings = append(warnings, w)
+			}
+			return &app, warnings
+		}
+
+		It("resolves the path relative to the manifest and uses the Procfile commands", func() {
+			writeFiles(map[string]string{
+				"deploy/manifest.yml": "name: app\npath: ../src\nprocesses:\n  - type: web\n  - type: worker\n    command: ./custom-worker\n",
+				"src/package.json":    `{"engines": {"node": "20.x"}}`,
+				"src/Procfile":        "web: node server.js\nworker: node worker.js\n",
+			})
+			app, warnings := discover(filepath.Join("deploy", "manifest.yml"))
+			Expect(warnings).To(BeEmpty())
+			Expect(app.Runtime).To(Equal(&Runtime{
+				Language:  NodeRuntimeLanguage,
+				Version:   "20.x",
+				BuildTool: "npm",
+				Procfile:  map[string]string{"web": "node server.js", "worker": "node worker.js"},
+			}))
+			Expect(app.Processes[0].Command).To(Equal("node server.js"))
+			Expect(app.Processes[1].Command).To(Equal("./custom-worker"))
+		})
+
+		It("inspects the manifest directory when the application does not define a path", func() {
+			writeFiles(map[string]string{"manifest.yml": "name: app\n", "go.mod": "module app\n\ngo 1.21\n"})
+			app, _ := discover("manifest.yml")
+			Expect(app.Runtime.Language).To(Equal(GoRuntimeLanguage))
+		})
+
+		It("reports a warning when the source does not exist", func() {
+			writeFiles(map[string]string{"manifest.yml": "name: app\npath: missing\n"})
+			app, warnings := discover("manifest.yml")
+			Expect(app.Runtime).To(BeNil())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(HaveField("Path", "path"))
+			Expect(warnings[0]).To(HaveField("Value", "missing"))
+		})
+	})
+})
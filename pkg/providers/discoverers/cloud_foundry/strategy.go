@@ -0,0 +1,169 @@
+package cloud_foundry
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
+	"gopkg.in/yaml.v3"
+)
+
+// DiscoveryStrategy selects how live discovery retrieves an application.
+type DiscoveryStrategy string
+
+const (
+	// ResourcesDiscoveryStrategy builds the application from its resources: environment, processes, routes, route
+	// services, sidecars and droplet. It is the default strategy and the one that captures the most information.
+	ResourcesDiscoveryStrategy DiscoveryStrategy = "resources"
+	// ManifestDiscoveryStrategy builds the application from the manifest generated by the Cloud Controller
+	// (`GET /v3/apps/:guid/manifest`) with a single request. The manifest contains neither the service credentials,
+	// nor the route destinations and route services, nor the autoscaling policy.
+	ManifestDiscoveryStrategy DiscoveryStrategy = "manifest"
+	// CombinedDiscoveryStrategy builds the application from its resources, completes it with the fields that only
+	// the generated manifest provides and reports each disagreement between both as a warning.
+	CombinedDiscoveryStrategy DiscoveryStrategy = "combined"
+)
+
+func validateStrategy(s DiscoveryStrategy) error {
+	switch s {
+	case "", ResourcesDiscoveryStrategy, ManifestDiscoveryStrategy, CombinedDiscoveryStrategy:
+		return nil
+	}
+	return fmt.Errorf("unknown discovery strategy %q: use %q, %q or %q", s, ResourcesDiscoveryStrategy, ManifestDiscoveryStrategy, CombinedDiscoveryStrategy)
+}
+
+// getGeneratedManifest retrieves the manifest that the Cloud Controller generates for the application, with its
+// quantities expressed as the resources of the API express them.
+func (c *CloudFoundryProvider) getGeneratedManifest(app *resource.App) (*cfTypes.AppManifest, error) {
+	body, err := c.cli.Manifests.Generate(context.Background(), app.GUID)
+	if err != nil {
+		return nil, err
+	}
+	var manifest cfTypes.CloudFoundryManifest
+	if err := yaml.Unmarshal([]byte(body), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode the manifest of app %s: %w", app.Name, err)
+	}
+	if len(manifest.Applications) == 0 || manifest.Applications[0] == nil {
+		return nil, fmt.Errorf("%w: the generated manifest of app %s has no applications", ErrAppNotFound, app.Name)
+	}
+	m := manifest.Applications[0]
+	normalizeQuantities(&m.AppManifestProcess)
+	if m.Processes != nil {
+		for i := range *m.Processes {
+			normalizeQuantities(&(*m.Processes)[i])
+			(*m.Processes)[i].Lifecycle = string(app.Lifecycle.Type)
+		}
+	}
+	if m.Sidecars != nil {
+		for i := range *m.Sidecars {
+			(*m.Sidecars)[i].Memory = scaleQuantity((*m.Sidecars)[i].Memory, megabyteScale)
+		}
+	}
+	if m.Routes == nil {
+		m.Routes = &cfTypes.AppManifestRoutes{}
+	}
+	return m, nil
+}
+
+// discoverFromGeneratedManifest builds the application from the manifest generated by the Cloud Controller.
+func (c *CloudFoundryProvider) discoverFromGeneratedManifest(spaceName string, app *resource.App) (*Application, []pTypes.Warning, error) {
+	m, err := c.getGeneratedManifest(app)
+	if err != nil {
+		return nil, nil, err
+	}
+	discoveredApp, warnings, err := c.parseApplication(spaceName, *m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &discoveredApp, warnings, nil
+}
+
+// discoverCombined builds the application from its resources and cross-checks it with the generated manifest. The
+// fields that only the manifest provides are copied to the application, and the fields where both disagree are
+// reported as warnings.
+func (c *CloudFoundryProvider) discoverCombined(spaceName string, app *resource.App) (*Application, []pTypes.Warning, error) {
+	fromManifest, _, err := c.discoverFromGeneratedManifest(spaceName, app)
+	if err != nil {
+		return nil, nil, err
+	}
+	discoveredApp, warnings, err := c.discoverFromResources(spaceName, app)
+	if err != nil {
+		return nil, nil, err
+	}
+	if discoveredApp.Features == nil {
+		discoveredApp.Features = fromManifest.Features
+	}
+	changes, err := DiffApplications(*fromManifest, *discoveredApp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return discoveredApp, append(warnings, disagreements(changes)...), nil
+}
+
+// disagreements returns a warning for each change between the application discovered from the generated manifest and
+// the one discovered from the resources. The fields that only one of them can provide, such as the service
+// credentials or the route destinations, are not reported: only the modified values and the list items missing from
+// either are.
+func disagreements(changes ChangeSet) []pTypes.Warning {
+	var warnings []pTypes.Warning
+	for _, ch := range changes.Changes {
+		var msg string
+		switch {
+		case ch.Type == ModifiedChangeType:
+			msg = fmt.Sprintf("the generated manifest reports %s and the resources report %s", renderValue(ch.Old), renderValue(ch.New))
+		case !strings.HasSuffix(ch.Path, "]"):
+			continue
+		case ch.Type == AddedChangeType:
+			msg = "only the resources report it"
+		default:
+			msg = "only the generated manifest reports it"
+		}
+		warnings = append(warnings, pTypes.Warning{Path: ch.Path, Value: ch.New, Message: msg})
+	}
+	return warnings
+}
+
+const (
+	byteScale     = 0
+	megabyteScale = 2
+)
+
+// manifestQuantity matches the quantities of the manifests, e.g. `256M`, `1G`, `16K` or `-1`.
+var manifestQuantity = regexp.MustCompile(`(?i)^\s*(-?\d+)\s*([KMGT]?)B?\s*$`)
+
+// normalizeQuantities expresses the memory and disk quota in megabytes and the log rate limit in bytes without unit,
+// as the resources of the API express them, so that both strategies discover the same values.
+func normalizeQuantities(p *cfTypes.AppManifestProcess) {
+	p.Memory = scaleQuantity(p.Memory, megabyteScale)
+	p.DiskQuota = scaleQuantity(p.DiskQuota, megabyteScale)
+	p.LogRateLimitPerSecond = scaleQuantity(p.LogRateLimitPerSecond, byteScale)
+}
+
+// scaleQuantity converts the quantity to the unit given as a power of 1024 of bytes, rounding up so that a quantity
+// smaller than the unit, e.g. `512K` in megabytes, is not reduced to 0. The values without unit, negative or not valid
+// are returned unchanged.
+func scaleQuantity(q string, scale int) string {
+	m := manifestQuantity.FindStringSubmatch(q)
+	if m == nil || m[2] == "" {
+		return q
+	}
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil || n < 0 {
+		return q
+	}
+	for e := strings.Index("BKMGT", strings.ToUpper(m[2])); e != scale; {
+		if e > scale {
+			n *= 1024
+			e--
+		} else {
+			n = (n + 1023) / 1024
+			e++
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,131 @@
+package cloud_foundry
+
+import (
+	"github.com/cloudfoundry/go-cfclient/v3/testutil"
+	"github.com/go-logr/logr"
+	cfTypes "github.com/konveyor/asset-generation/internal/models"
+	pTypes "github.com/konveyor/asset-generation/pkg/providers/types/provider"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const generatedManifest = `---
+applications:
+- name: app
+  stack: cflinuxfs4
+  features:
+    ssh: true
+  routes:
+  - route: app.example.com
+    protocol: http1
+  - route: app.internal.example.com
+    protocol: http1
+  processes:
+  - type: web
+    instances: 3
+    memory: 1G
+    disk_quota: 2G
+    log-rate-limit-per-second: 16K
+    health-check-type: port
+`
+
+var _ = Describe("Discovery strategies", func() {
+	var logger = logr.New(logr.Discard().GetSink())
+
+	discover := func(strategy DiscoveryStrategy, app cfTypes.AppManifest, opts ...mockOption) (Application, []pTypes.Warning) {
+		discovered, result := discoverLiveApplication(GlobalT, Config{Strategy: strategy}, false, app, opts...)
+		return discovered, result.Warnings
+	}
+
+	liveApp := func() cfTypes.AppManifest {
+		instances := uint(2)
+		return cfTypes.AppManifest{
+			Name:       "app",
+			Metadata:   &cfTypes.AppMetadata{},
+			Stack:      "cflinuxfs4",
+			Buildpacks: []string{"java_buildpack"},
+			Routes:     &cfTypes.AppManifestRoutes{{Route: "app.example.com", Protocol: cfTypes.HTTP1}},
+			Processes: &cfTypes.AppManifestProcesses{
+				{Type: "web", Instances: &instances, Memory: "1024", DiskQuota: "2048", LogRateLimitPerSecond: "16384", HealthCheckType: "port"},
+			},
+		}
+	}
+
+	AfterEach(func() {
+		testutil.Teardown()
+	})
+
+	It("discovers the application from the generated manifest", func() {
+		app, warnings := discover(ManifestDiscoveryStrategy, liveApp(), withGeneratedManifest(generatedManifest))
+		Expect(warnings).To(BeEmpty())
+		Expect(app.Name).To(Equal("app"))
+		Expect(app.Features).To(Equal(map[string]bool{"ssh": true}))
+		Expect(app.Routes.Routes).To(Equal(Routes{
+			{Route: "app.example.com", Protocol: HTTPRouteProtocol},
+			{Route: "app.internal.example.com", Protocol: HTTPRouteProtocol},
+		}))
+		Expect(app.Processes).To(HaveLen(1))
+		By("expressing the quantities as the resources of the API")
+		Expect(app.Processes[0].Instances).To(Equal(3))
+		Expect(app.Processes[0].Memory).To(Equal("1024"))
+		Expect(app.Processes[0].DiskQuota).To(Equal("2048"))
+		Expect(app.Processes[0].LogRateLimit).To(Equal("16384"))
+		Expect(app.Processes[0].Lifecycle).To(Equal(BuildPackLifecycleType))
+	})
+
+	It("discovers the same application with both strategies when they agree", func() {
+		fromResources, warnings := discover(ResourcesDiscoveryStrategy, liveApp())
+		Expect(warnings).To(BeEmpty())
+		testutil.Teardown()
+		fromManifest, warnings := discover(ManifestDiscoveryStrategy, liveApp())
+		Expect(warnings).To(BeEmpty())
+		// Each discovery runs against a different mock foundation
+		fromManifest.Space = fromResources.Space
+		changes, err := DiffApplications(fromManifest, fromResources)
+		Expect(err).NotTo(HaveOccurred())
+		By("differing only in the fields that the manifest cannot represent")
+		Expect(changes.Changes).To(ConsistOf(HaveField("Path", "routes.routes[app.example.com].destinations")))
+		Expect(disagreements(changes)).To(BeEmpty())
+	})
+
+	It("combines both strategies and reports where they disagree", func() {
+		app, warnings := discover(CombinedDiscoveryStrategy, liveApp(), withGeneratedManifest(generatedManifest))
+		By("keeping the values of the resources")
+		Expect(app.Processes[0].Instances).To(Equal(2))
+		Expect(app.Routes.Routes).To(HaveLen(1))
+		By("completing them with the fields that only the manifest provides")
+		Expect(app.Features).To(Equal(map[string]bool{"ssh": true}))
+		Expect(warnings).To(ConsistOf(
+			pTypes.Warning{
+				Path:    "processes[web].instances",
+				Value:   float64(2),
+				Message: "the generated manifest reports 3 and the resources report 2",
+			},
+			pTypes.Warning{
+				Path:    "routes.routes[app.internal.example.com]",
+				Message: "only the generated manifest reports it",
+			},
+		))
+	})
+
+	It("rejects an unknown strategy", func() {
+		_, err := New(&Config{ManifestPath: "manifest.yml", Strategy: "scraping"}, &logger, false)
+		Expect(err).To(MatchError(ContainSubstring(`unknown discovery strategy "scraping"`)))
+	})
+
+	DescribeTable("normalizes the quantities of the generated manifest", func(quantity string, scale int, expected string) {
+		Expect(scaleQuantity(quantity, scale)).To(Equal(expected))
+	},
+		Entry("megabytes", "256M", megabyteScale, "256"),
+		Entry("megabytes with the long unit", "256MB", megabyteScale, "256"),
+		Entry("gigabytes", "2G", megabyteScale, "2048"),
+		Entry("kilobytes to bytes", "16K", byteScale, "16384"),
+		Entry("megabytes to bytes", "1M", byteScale, "1048576"),
+		Entry("kilobytes to megabytes", "2048K", megabyteScale, "2"),
+		Entry("less than a megabyte", "512K", megabyteScale, "1"),
+		Entry("a fraction of a megabyte", "1025K", megabyteScale, "2"),
+		Entry("a value without unit", "512", megabyteScale, "512"),
+		Entry("an unlimited log rate", "-1", byteScale, "-1"),
+		Entry("an invalid value", "lots", megabyteScale, "lots"),
+	)
+})
@@ -27,6 +27,111 @@ type Application struct {
 	Path string `yaml:"path,omitempty" json:"path,omitempty" validate:"omitempty"`
 	// Feature represents a map of key/value pairs of the app feature names to boolean values indicating whether the feature is enabled or not
 	Features map[string]bool `yaml:"features,omitempty" json:"features,omitempty" validate:"omitempty"`
+	// SSH captures whether the runtime of the application accepts SSH connections and, when it does not, whether SSH
+	// is disabled globally, for the space or for the application. It is only discovered from a live foundation.
+	SSH *SSHSettings `yaml:"ssh,omitempty" json:"ssh,omitempty"`
+	// Revisions captures the deployed revisions of the application, by version. It is only discovered from a live
+	// foundation, for the applications with the `revisions` feature enabled.
+	Revisions []Revision `yaml:"revisions,omitempty" json:"revisions,omitempty"`
+	// Droplet captures the current droplet of the application: the buildpacks that staged it, its process types and
+	// checksum, and the package it was staged from. It is only discovered from a live foundation, for the applications
+	// that are not docker applications.
+	Droplet *Droplet `yaml:"droplet,omitempty" json:"droplet,omitempty"`
+	// Runtime captures the language runtime and framework detected by inspecting the application source at `path`.
+	// It is only set for local discovery with source inspection enabled.
+	Runtime *Runtime `yaml:"runtime,omitempty" json:"runtime,omitempty" validate:"omitempty"`
+	// ContainerImage captures the container images and Cloud Native Buildpacks recommended to build the application,
+	// resolved from its buildpacks and stack with the image catalog. It is only set when image resolution is enabled.
+	ContainerImage *ContainerImage `yaml:"containerImage,omitempty" json:"containerImage,omitempty" validate:"omitempty"`
+	// MigrationHints captures the Cloud Foundry platform dependencies of the application, such as the Spring Cloud
+	// Services it binds to, with the Kubernetes replacement they require. It is only set when the detection of
+	// platform dependencies is enabled.
+	MigrationHints []MigrationHint `yaml:"migrationHints,omitempty" json:"migrationHints,omitempty" validate:"omitempty"`
+}
+
+// Names of the application features of the Cloud Foundry API.
+// https://v3-apidocs.cloudfoundry.org/version/3.192.0/index.html#supported-app-features
+const (
+	SSHFeature                   = "ssh"
+	RevisionsFeature             = "revisions"
+	ServiceBindingK8sFeature     = "service-binding-k8s"
+	FileBasedVCAPServicesFeature = "file-based-vcap-services"
+)
+
+type SSHSettings struct {
+	// Enabled captures whether the application accepts SSH connections.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Reason captures why SSH is disabled, e.g. `Disabled for space dev`. Empty when SSH is enabled.
+	Reason string `yaml:"reason,omitempty" json:"reason,omitempty"`
+}
+
+type Revision struct {
+	// Version captures the version of the revision, incremented on each new revision of the application.
+	Version int `yaml:"version" json:"version"`
+	// Description captures the changes that created the revision, e.g. `New droplet deployed.`.
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Droplet captures the GUID of the droplet of the revision.
+	Droplet string `yaml:"droplet,omitempty" json:"droplet,omitempty"`
+	// Deployable captures whether the revision can be deployed again.
+	Deployable bool `yaml:"deployable" json:"deployable"`
+	// CreatedAt captures the creation time of the revision in RFC 3339 format.
+	CreatedAt string `yaml:"createdAt,omitempty" json:"createdAt,omitempty"`
+}
+
+type Droplet struct {
+	// GUID captures the GUID of the droplet.
+	GUID string `yaml:"guid" json:"guid"`
+	// State captures the state of the droplet, e.g. `STAGED`.
+	State string `yaml:"state,omitempty" json:"state,omitempty"`
+	// Stack captures the stack the droplet was staged on, e.g. `cflinuxfs4`.
+	Stack string `yaml:"stack,omitempty" json:"stack,omitempty"`
+	// Buildpacks captures the buildpacks that staged the droplet, in order, with their version.
+	Buildpacks []DetectedBuildpack `yaml:"buildpacks,omitempty" json:"buildpacks,omitempty"`
+	// StartCommand captures the start command of the web process detected by the buildpacks.
+	StartCommand string `yaml:"startCommand,omitempty" json:"startCommand,omitempty"`
+	// ProcessTypes captures the commands of the process types the droplet provides, by process type, e.g. from a
+	// Procfile.
+	ProcessTypes map[string]string `yaml:"processTypes,omitempty" json:"processTypes,omitempty"`
+	// Checksum captures the checksum of the droplet bits.
+	Checksum *Checksum `yaml:"checksum,omitempty" json:"checksum,omitempty"`
+	// CreatedAt captures the creation time of the droplet in RFC 3339 format.
+	CreatedAt string `yaml:"createdAt,omitempty" json:"createdAt,omitempty"`
+	// Package captures the package the droplet was staged from. Nil when the package no longer exists.
+	Package *Package `yaml:"package,omitempty" json:"package,omitempty"`
+	// File captures the path of the droplet bits downloaded during the discovery. Only set when the download of the
+	// droplets is enabled.
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+}
+
+type DetectedBuildpack struct {
+	// Name captures the name of the buildpack in the foundation, or its URL, e.g. `java_buildpack_offline`.
+	Name string `yaml:"name" json:"name"`
+	// BuildpackName captures the name reported by the buildpack, e.g. `java`.
+	BuildpackName string `yaml:"buildpackName,omitempty" json:"buildpackName,omitempty"`
+	// Version captures the version reported by the buildpack, e.g. `4.77.0`.
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+	// DetectOutput captures the output of the detection of the buildpack.
+	DetectOutput string `yaml:"detectOutput,omitempty" json:"detectOutput,omitempty"`
+}
+
+type Package struct {
+	// GUID captures the GUID of the package.
+	GUID string `yaml:"guid" json:"guid"`
+	// Type captures the type of the package, `bits` for the uploaded application source.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// State captures the state of the package, e.g. `READY`.
+	State string `yaml:"state,omitempty" json:"state,omitempty"`
+	// Checksum captures the checksum of the package bits.
+	Checksum *Checksum `yaml:"checksum,omitempty" json:"checksum,omitempty"`
+	// CreatedAt captures the creation time of the package in RFC 3339 format.
+	CreatedAt string `yaml:"createdAt,omitempty" json:"createdAt,omitempty"`
+}
+
+type Checksum struct {
+	// Type captures the hash algorithm of the checksum, e.g. `sha256`.
+	Type string `yaml:"type" json:"type"`
+	// Value captures the hexadecimal value of the checksum.
+	Value string `yaml:"value" json:"value"`
 }
 
 type Services []ServiceSpec
@@ -38,6 +143,12 @@ type Docker struct {
 	Image string `yaml:"image,omitempty" json:"image,omitempty" validate:"required"`
 	// Username captures the username to authenticate against the container registry.
 	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	// Registry captures the host of the container registry of the image, e.g. `registry.example.com:5000`. The images
+	// without registry host are pulled from Docker Hub, `docker.io`.
+	Registry string `yaml:"registry,omitempty" json:"registry,omitempty"`
+	// PrivateRegistry captures whether pulling the image requires credentials, because the registry is not a public
+	// one or a username is set.
+	PrivateRegistry bool `yaml:"privateRegistry,omitempty" json:"privateRegistry,omitempty"`
 }
 
 type SidecarSpec struct {
@@ -67,6 +178,9 @@ type ServiceSpec struct {
 	Parameters map[string]interface{} `yaml:"parameters,omitempty" json:"parameters,omitempty"`
 	// BindingName captures the name of the service to bind to.
 	BindingName string `yaml:"bindingName,omitempty" json:"bindingName,omitempty"`
+	// Label captures the service offering of the instance, e.g. `p.config-server`, as labelled in VCAP_SERVICES. It is
+	// only discovered from a live foundation.
+	Label string `yaml:"label,omitempty" json:"label,omitempty"`
 }
 
 type Metadata struct {
@@ -89,6 +203,74 @@ type ProcessSpec struct {
 	Type ProcessType `yaml:"type" json:"type" validate:"required,oneof=web worker"`
 
 	ProcessSpecTemplate `yaml:",inline" json:",inline" validate:"omitempty"`
+	// Autoscaling captures the App Autoscaler policy that scales the process, when the application is bound to the
+	// App Autoscaler. It is only discovered from a live foundation and only applies to the web process.
+	Autoscaling *AutoscalingPolicy `yaml:"autoscaling,omitempty" json:"autoscaling,omitempty" validate:"omitempty"`
+}
+
+type AutoscalingPolicy struct {
+	// MinInstances captures the minimum number of instances of the process.
+	MinInstances int `yaml:"minInstances" json:"minInstances"`
+	// MaxInstances captures the maximum number of instances of the process.
+	MaxInstances int `yaml:"maxInstances" json:"maxInstances"`
+	// Rules captures the dynamic scaling rules based on metrics.
+	Rules []ScalingRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+	// Schedules captures the instance limits that apply during recurring periods or specific dates.
+	Schedules *ScalingSchedules `yaml:"schedules,omitempty" json:"schedules,omitempty"`
+}
+
+type ScalingRule struct {
+	// MetricType captures the metric that triggers the rule: `memoryused`, `memoryutil`, `cpu`, `cpuutil`, `disk`,
+	// `diskutil`, `responsetime`, `throughput` or the name of a custom metric.
+	MetricType string `yaml:"metricType" json:"metricType"`
+	// Threshold captures the value of the metric compared with the operator.
+	Threshold int64 `yaml:"threshold" json:"threshold"`
+	// Operator captures the comparison of the metric with the threshold: `<`, `>`, `<=` or `>=`.
+	Operator string `yaml:"operator" json:"operator"`
+	// Adjustment captures the change of the number of instances, e.g. `+1` or `-50%`.
+	Adjustment string `yaml:"adjustment" json:"adjustment"`
+	// BreachDurationSeconds captures how long the threshold must be breached before scaling.
+	BreachDurationSeconds int `yaml:"breachDurationSeconds,omitempty" json:"breachDurationSeconds,omitempty"`
+	// CoolDownSeconds captures the minimum time between two scaling events.
+	CoolDownSeconds int `yaml:"coolDownSeconds,omitempty" json:"coolDownSeconds,omitempty"`
+}
+
+type ScalingSchedules struct {
+	// Timezone captures the time zone of the schedules, e.g. `Europe/Madrid`.
+	Timezone string `yaml:"timezone" json:"timezone"`
+	// Recurring captures the schedules that repeat on days of the week or of the month.
+	Recurring []RecurringSchedule `yaml:"recurring,omitempty" json:"recurring,omitempty"`
+	// SpecificDates captures the schedules that apply to a period of time.
+	SpecificDates []SpecificDateSchedule `yaml:"specificDates,omitempty" json:"specificDates,omitempty"`
+}
+
+type RecurringSchedule struct {
+	// StartTime and EndTime capture the time of the day when the schedule starts and ends, e.g. `08:00`.
+	StartTime string `yaml:"startTime" json:"startTime"`
+	EndTime   string `yaml:"endTime" json:"endTime"`
+	// StartDate and EndDate capture the optional period when the schedule is active, e.g. `2025-01-31`.
+	StartDate string `yaml:"startDate,omitempty" json:"startDate,omitempty"`
+	EndDate   string `yaml:"endDate,omitempty" json:"endDate,omitempty"`
+	// DaysOfWeek captures the days of the week when the schedule applies, from 1 (Monday) to 7 (Sunday).
+	DaysOfWeek []int `yaml:"daysOfWeek,omitempty" json:"daysOfWeek,omitempty"`
+	// DaysOfMonth captures the days of the month when the schedule applies.
+	DaysOfMonth []int `yaml:"daysOfMonth,omitempty" json:"daysOfMonth,omitempty"`
+	// MinInstances and MaxInstances capture the limits of the number of instances during the schedule.
+	MinInstances int `yaml:"minInstances" json:"minInstances"`
+	MaxInstances int `yaml:"maxInstances" json:"maxInstances"`
+	// InitialMinInstances captures the minimum number of instances when the schedule starts.
+	InitialMinInstances int `yaml:"initialMinInstances,omitempty" json:"initialMinInstances,omitempty"`
+}
+
+type SpecificDateSchedule struct {
+	// StartDateTime and EndDateTime capture when the schedule starts and ends, e.g. `2025-12-24T08:00`.
+	StartDateTime string `yaml:"startDateTime" json:"startDateTime"`
+	EndDateTime   string `yaml:"endDateTime" json:"endDateTime"`
+	// MinInstances and MaxInstances capture the limits of the number of instances during the schedule.
+	MinInstances int `yaml:"minInstances" json:"minInstances"`
+	MaxInstances int `yaml:"maxInstances" json:"maxInstances"`
+	// InitialMinInstances captures the minimum number of instances when the schedule starts.
+	InitialMinInstances int `yaml:"initialMinInstances,omitempty" json:"initialMinInstances,omitempty"`
 }
 
 type ProcessSpecTemplate struct {
@@ -179,14 +361,45 @@ type Route struct {
 	// Protocol captures the protocol type: http, http2 or tcp. Note that the CF `protocol` field is only available
 	// for CF deployments that use HTTP/2 routing.
 	Protocol RouteProtocol `yaml:"protocol,omitempty" json:"protocol,omitempty" validate:"omitempty,oneof=http1 http2 tcp"`
-	// Options captures the options for the Route. Only load balancing is supported at the moment.
+	// Options captures the load balancing options of the Route.
 	Options RouteOptions `yaml:"options,omitempty" json:"options,omitempty" validate:"omitempty"`
+	// RouteServiceURL captures the URL of the route service bound to the route, which receives the requests before
+	// they are forwarded to the application. It is only discovered from a live foundation.
+	RouteServiceURL string `yaml:"routeServiceURL,omitempty" json:"routeServiceURL,omitempty"`
+	// Destinations captures the applications and processes that receive the traffic of the route, with their port
+	// and weight. It is only discovered from a live foundation.
+	Destinations []RouteDestination `yaml:"destinations,omitempty" json:"destinations,omitempty"`
+	// Shared captures whether the route sends the traffic to more than one application or process type, e.g. the
+	// route of a blue/green deployment, which should be exposed as a single logical service. It is only discovered
+	// from a live foundation.
+	Shared bool `yaml:"shared,omitempty" json:"shared,omitempty"`
 }
 
 type RouteOptions struct {
-	// LoadBalancing captures the settings for load balancing. Only `round-robin` or `least-connection` are supported
+	// LoadBalancing captures the settings for load balancing: `round-robin`, `least-connection` or `hash`.
 	// https://v3-apidocs.cloudfoundry.org/version/3.192.0/index.html#the-route-options-object
-	LoadBalancing LoadBalancingType `yaml:"loadBalancing,omitempty" json:"loadBalancing,omitempty" validate:"omitempty,oneof=round-robin least-connection"`
+	LoadBalancing LoadBalancingType `yaml:"loadBalancing,omitempty" json:"loadBalancing,omitempty" validate:"omitempty,oneof=round-robin least-connection hash"`
+	// HashHeader captures the request header whose value selects the instance that receives the request, giving
+	// session affinity when the load balancing is `hash`.
+	HashHeader string `yaml:"hashHeader,omitempty" json:"hashHeader,omitempty"`
+	// HashBalance captures how much the load of an instance can exceed the average load before the requests are
+	// sent to the next instance, e.g. `1.25`, when the load balancing is `hash`.
+	HashBalance string `yaml:"hashBalance,omitempty" json:"hashBalance,omitempty"`
+}
+
+type RouteDestination struct {
+	// App captures the name of the application that receives the traffic. Routes shared by several applications,
+	// e.g. in blue/green deployments, have destinations for each of them.
+	App string `yaml:"app,omitempty" json:"app,omitempty"`
+	// ProcessType captures the process of the application that receives the traffic. Defaults to `web`.
+	ProcessType ProcessType `yaml:"processType,omitempty" json:"processType,omitempty"`
+	// Port captures the port of the application that receives the traffic. Defaults to 8080.
+	Port int `yaml:"port,omitempty" json:"port,omitempty"`
+	// Weight captures the percentage of the traffic of the route sent to the destination, when the traffic is split
+	// between several destinations.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+	// Protocol captures the protocol used to send the traffic to the destination: `http1`, `http2` or `tcp`.
+	Protocol RouteProtocol `yaml:"protocol,omitempty" json:"protocol,omitempty"`
 }
 
 type LoadBalancingType string
@@ -194,6 +407,7 @@ type LoadBalancingType string
 const (
 	RoundRobinLoadBalancingType      LoadBalancingType = "round-robin"
 	LeastConnectionLoadBalancingType LoadBalancingType = "least-connection"
+	HashLoadBalancingType            LoadBalancingType = "hash"
 )
 
 type RouteProtocol string
@@ -203,3 +417,75 @@ const (
 	HTTP2RouteProtocol RouteProtocol = "http2"
 	TCPRouteProtocol   RouteProtocol = "tcp"
 )
+
+type Runtime struct {
+	// Language represents the language of the application source: `java`, `node`, `python`, `ruby` or `go`. Empty
+	// when the source only contains a Procfile.
+	Language RuntimeLanguage `yaml:"language,omitempty" json:"language,omitempty" validate:"omitempty,oneof=java node python ruby go"`
+	// Version captures the version of the language runtime required by the source, e.g. `17` for Java or `>=18`
+	// for Node.js. Empty when the source does not declare it.
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+	// BuildTool captures the tool used to build the source, e.g. `maven`, `gradle`, `npm`, `pip`, `pipenv`,
+	// `bundler` or `go`.
+	BuildTool string `yaml:"buildTool,omitempty" json:"buildTool,omitempty"`
+	// Framework captures the main framework used by the application, e.g. `spring-boot`, `express` or `rails`.
+	Framework string `yaml:"framework,omitempty" json:"framework,omitempty"`
+	// FrameworkVersion captures the version of the framework when the source declares it.
+	FrameworkVersion string `yaml:"frameworkVersion,omitempty" json:"frameworkVersion,omitempty"`
+	// Procfile captures the commands of the process types declared in the `Procfile` of the source.
+	Procfile map[string]string `yaml:"procfile,omitempty" json:"procfile,omitempty"`
+}
+
+type RuntimeLanguage string
+
+const (
+	JavaRuntimeLanguage   RuntimeLanguage = "java"
+	NodeRuntimeLanguage   RuntimeLanguage = "node"
+	PythonRuntimeLanguage RuntimeLanguage = "python"
+	RubyRuntimeLanguage   RuntimeLanguage = "ruby"
+	GoRuntimeLanguage     RuntimeLanguage = "go"
+)
+
+type ContainerImage struct {
+	// Entry captures the name of the image catalog entry matching the buildpack that runs the application, e.g.
+	// `java`.
+	Entry string `yaml:"entry,omitempty" json:"entry,omitempty"`
+	// BaseImage represents the recommended image to run the application.
+	BaseImage string `yaml:"baseImage,omitempty" json:"baseImage,omitempty"`
+	// BuildImage represents the recommended image to build the application in a multi-stage build. Empty when the
+	// base image can also build the application.
+	BuildImage string `yaml:"buildImage,omitempty" json:"buildImage,omitempty"`
+	// Builder represents the Cloud Native Buildpacks builder recommended for the stack of the application.
+	Builder string `yaml:"builder,omitempty" json:"builder,omitempty"`
+	// Buildpacks captures the Cloud Native Buildpacks that replace the buildpacks of the application, in order.
+	Buildpacks []string `yaml:"buildpacks,omitempty" json:"buildpacks,omitempty"`
+	// UnmappedBuildpacks captures the buildpacks of the application that are not in the image catalog.
+	UnmappedBuildpacks []string `yaml:"unmappedBuildpacks,omitempty" json:"unmappedBuildpacks,omitempty"`
+}
+
+type MigrationHint struct {
+	// Dependency identifies the platform dependency, e.g. `config-server` or `service-registry`.
+	Dependency PlatformDependency `yaml:"dependency" json:"dependency"`
+	// Source captures where the dependency was found: the service instance, e.g. `services[config-server]`, or the
+	// environment variable, e.g. `env.SPRING_PROFILES_ACTIVE`.
+	Source string `yaml:"source" json:"source"`
+	// Replacement describes the Kubernetes resource or library that replaces the dependency.
+	Replacement string `yaml:"replacement" json:"replacement"`
+	// Message describes the changes required to migrate the dependency.
+	Message string `yaml:"message" json:"message"`
+}
+
+type PlatformDependency string
+
+const (
+	// ConfigServerDependency represents the Spring Cloud Config Server.
+	ConfigServerDependency PlatformDependency = "config-server"
+	// ServiceRegistryDependency represents the Eureka service registry.
+	ServiceRegistryDependency PlatformDependency = "service-registry"
+	// CircuitBreakerDashboardDependency represents the Hystrix circuit breaker dashboard.
+	CircuitBreakerDashboardDependency PlatformDependency = "circuit-breaker-dashboard"
+	// SpringProfilesDependency represents the Spring profiles activated for the platform.
+	SpringProfilesDependency PlatformDependency = "spring-profiles"
+	// JavaBuildpackConfigDependency represents the configuration of the Java buildpack in `JBP_CONFIG_*` variables.
+	JavaBuildpackConfigDependency PlatformDependency = "java-buildpack-config"
+)
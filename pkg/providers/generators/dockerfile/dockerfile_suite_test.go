@@ -0,0 +1,13 @@
+package dockerfile_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDockerfile(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dockerfile Suite")
+}
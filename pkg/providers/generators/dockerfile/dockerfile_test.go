@@ -0,0 +1,211 @@
+package dockerfile_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	cf "github.com/konveyor/asset-generation/pkg/providers/discoverers/cloud_foundry"
+	"github.com/konveyor/asset-generation/pkg/providers/generators/dockerfile"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dockerfile generator", func() {
+	javaApp := func() cf.Application {
+		return cf.Application{
+			Metadata:   cf.Metadata{Name: "spring-music"},
+			BuildPacks: []string{"java_buildpack"},
+			Path:       "build/libs/spring-music-1.0.jar",
+			Env: map[string]string{
+				"SPRING_PROFILES_ACTIVE": "http2",
+				"DB_PASSWORD":            "s3cr3t",
+				"GREETING":               `say "hi" to $USER`,
+			},
+			Routes: cf.RouteSpec{Routes: cf.Routes{
+				{Route: "spring-music.example.com"},
+				{Route: "tcp.example.com:1024", Protocol: cf.TCPRouteProtocol},
+			}},
+			Processes: cf.Processes{
+				{Type: cf.Web, ProcessSpecTemplate: cf.ProcessSpecTemplate{Command: "java -jar app.jar && echo done"}},
+				{Type: cf.Worker, ProcessSpecTemplate: cf.ProcessSpecTemplate{Command: "java -cp app.jar Worker"}},
+			},
+		}
+	}
+
+	generate := func(cfg dockerfile.Config) map[string]string {
+		files, err := dockerfile.New(cfg).Generate()
+		Expect(err).NotTo(HaveOccurred())
+		return files
+	}
+
+	It("generates a Dockerfile from the template of the buildpack", func() {
+		files := generate(dockerfile.Config{Application: javaApp()})
+		Expect(files).To(HaveKeyWithValue("Dockerfile", `# Dockerfile generated for the Cloud Foundry application spring-music
+# Buildpacks: paketo-buildpacks/java
+FROM registry.access.redhat.com/ubi9/openjdk-17-runtime
+WORKDIR /deployments
+COPY build/libs/spring-music-1.0.jar /deployments/spring-music-1.0.jar
+ENV GREETING="say \"hi\" to \$USER"
+ENV PORT="8080"
+ENV SPRING_PROFILES_ACTIVE="http2"
+# DB_PASSWORD is not set because it may contain sensitive information
+EXPOSE 1024
+EXPOSE 8080
+# The TCP route tcp.example.com:1024 forwards to the application port
+# Command of the web process: java -jar app.jar && echo done
+# Command of the worker process: java -cp app.jar Worker
+ENTRYPOINT ["/bin/sh", "-c"]
+CMD ["java -jar app.jar && echo done"]
+`))
+	})
+
+	It("exposes the ports of the destinations and of the TCP routes", func() {
+		app := javaApp()
+		app.Routes = cf.RouteSpec{Routes: cf.Routes{
+			{Route: "spring-music.example.com", Destinations: []cf.RouteDestination{
+				{App: "spring-music", Port: 9090},
+				{App: "spring-music-green", Port: 9191},
+			}},
+			{Route: "metrics.example.com", Destinations: []cf.RouteDestination{{ProcessType: cf.Web}}},
+			{Route: "tcp.example.com:1024", Protocol: cf.TCPRouteProtocol, Destinations: []cf.RouteDestination{{Port: 5432}}},
+		}}
+		files := generate(dockerfile.Config{Application: app})
+		Expect(files["Dockerfile"]).To(ContainSubstring("EXPOSE 1024\nEXPOSE 5432\nEXPOSE 8080\nEXPOSE 9090\n"))
+	})
+
+	It("generates a multi-stage Dockerfile for Go applications detected by source inspection", func() {
+		files := generate(dockerfile.Config{Application: cf.Application{
+			Metadata: cf.Metadata{Name: "api"},
+			Runtime:  &cf.Runtime{Language: cf.GoRuntimeLanguage},
+			Routes:   cf.RouteSpec{NoRoute: true},
+		}})
+		Expect(files["Dockerfile"]).To(Equal(`# Dockerfile generated for the Cloud Foundry application api
+# Buildpacks: paketo-buildpacks/go
+FROM registry.access.redhat.com/ubi9/go-toolset AS build
+WORKDIR /opt/app-root/src
+COPY --chown=1001 . ./
+RUN CGO_ENABLED=0 go build -o /tmp/api .
+
+FROM registry.access.redhat.com/ubi9/ubi-minimal
+WORKDIR /app
+COPY --from=build /tmp/api /app/api
+CMD ["/app/api"]
+`))
+	})
+
+	It("builds the Java applications without artifact in a build stage", func() {
+		app := javaApp()
+		app.Path = "."
+		files := generate(dockerfile.Config{Application: app})
+		Expect(files["Dockerfile"]).To(ContainSubstring(`FROM registry.access.redhat.com/ubi9/openjdk-17 AS build
+WORKDIR /tmp/src
+COPY --chown=185 . ./
+`))
+		Expect(files["Dockerfile"]).To(ContainSubstring(`FROM registry.access.redhat.com/ubi9/openjdk-17-runtime
+WORKDIR /deployments
+COPY --from=build /tmp/app.jar /deployments/app.jar
+`))
+	})
+
+	It("uses the default template for the buildpacks without a dedicated template", func() {
+		files := generate(dockerfile.Config{Application: cf.Application{
+			Metadata:   cf.Metadata{Name: "site"},
+			BuildPacks: []string{"staticfile_buildpack"},
+		}})
+		Expect(files["Dockerfile"]).To(ContainSubstring("FROM registry.access.redhat.com/ubi9/nginx-124\nWORKDIR /app\nCOPY . /app/\n"))
+	})
+
+	It("generates a project.toml for Cloud Native Buildpacks", func() {
+		app := javaApp()
+		app.Stack = "cflinuxfs4"
+		files := generate(dockerfile.Config{Application: app, Format: dockerfile.CNBFormat})
+		Expect(files).To(HaveKeyWithValue("project.toml", `# Cloud Native Buildpacks project descriptor generated for the Cloud Foundry application spring-music
+[_]
+schema-version = "0.2"
+id = "spring-music"
+
+[io.buildpacks]
+builder = "paketobuildpacks/builder-jammy-base"
+
+[[io.buildpacks.group]]
+id = "paketo-buildpacks/java"
+
+[[io.buildpacks.build.env]]
+name = "GREETING"
+value = "say \"hi\" to $USER"
+
+[[io.buildpacks.build.env]]
+name = "PORT"
+value = "8080"
+
+[[io.buildpacks.build.env]]
+name = "SPRING_PROFILES_ACTIVE"
+value = "http2"
+# DB_PASSWORD is not set because it may contain sensitive information
+# Command of the web process: java -jar app.jar && echo done
+# Command of the worker process: java -cp app.jar Worker
+`))
+	})
+
+	It("escapes the values of the project.toml as TOML strings", func() {
+		app := javaApp()
+		app.Env = map[string]string{"BANNER": "caf\u00e9\t\x01\\"}
+		files := generate(dockerfile.Config{Application: app, Format: dockerfile.CNBFormat})
+		Expect(files["project.toml"]).To(ContainSubstring(`value = "café\t\u0001\\"`))
+	})
+
+	It("continues the comment of the multi-line commands", func() {
+		app := javaApp()
+		app.Processes = cf.Processes{{Type: cf.Web, ProcessSpecTemplate: cf.ProcessSpecTemplate{Command: "npm run migrate\nnpm start\n"}}}
+		expected := `# Command of the web process: npm run migrate
+#   npm start
+`
+		files := generate(dockerfile.Config{Application: app})
+		Expect(files["Dockerfile"]).To(ContainSubstring(expected))
+		Expect(files["Dockerfile"]).To(HaveSuffix(`CMD ["npm run migrate\nnpm start\n"]` + "\n"))
+		files = generate(dockerfile.Config{Application: app, Format: dockerfile.CNBFormat})
+		Expect(files["project.toml"]).To(HaveSuffix(expected))
+		var descriptor map[string]any
+		Expect(toml.Unmarshal([]byte(files["project.toml"]), &descriptor)).To(Succeed())
+	})
+
+	It("uses the templates of the templates directory", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "java.Dockerfile.tmpl"), []byte(`{{- template "header" . }}
+FROM registry.example.com/java:21
+COPY {{ .Source }} /app.jar
+{{- template "command" . }}
+`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "common.tmpl"), []byte(`{{- define "header" }}# {{ .Name }}{{ end }}
+{{- define "command" }}
+CMD {{ exec .Command }}
+{{- end }}`), 0644)).To(Succeed())
+		files := generate(dockerfile.Config{Application: javaApp(), TemplatesDir: dir})
+		Expect(files["Dockerfile"]).To(Equal(`# spring-music
+FROM registry.example.com/java:21
+COPY build/libs/spring-music-1.0.jar /app.jar
+CMD ["java -jar app.jar && echo done"]
+`))
+	})
+
+	It("uses the container image resolved during discovery", func() {
+		app := javaApp()
+		app.ContainerImage = &cf.ContainerImage{Entry: "java", BaseImage: "registry.example.com/java:17"}
+		Expect(generate(dockerfile.Config{Application: app})["Dockerfile"]).To(ContainSubstring("FROM registry.example.com/java:17\n"))
+	})
+
+	DescribeTable("fails to generate a Dockerfile", func(app cf.Application, expected string) {
+		_, err := dockerfile.New(dockerfile.Config{Application: app}).Generate()
+		Expect(err).To(MatchError(expected))
+	},
+		Entry("for a Docker application", cf.Application{Metadata: cf.Metadata{Name: "app"}, Docker: cf.Docker{Image: "nginx:latest"}},
+			"application app already runs the container image nginx:latest"),
+		Entry("for a Go application without build image", cf.Application{
+			Metadata:       cf.Metadata{Name: "api"},
+			ContainerImage: &cf.ContainerImage{Entry: "go", BaseImage: "registry.access.redhat.com/ubi9/ubi-minimal"},
+		}, `failed to render the template go.Dockerfile.tmpl: template: go.Dockerfile.tmpl:2:8: executing "go.Dockerfile.tmpl" at <required "build image" .BuildImage>: error calling required: no build image found: add it to the image catalog entry`),
+		Entry("for unknown buildpacks", cf.Application{Metadata: cf.Metadata{Name: "app"}, BuildPacks: []string{"custom"}},
+			"no base image found for application app: add its buildpacks to the image catalog"),
+	)
+})
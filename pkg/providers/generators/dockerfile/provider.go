@@ -0,0 +1,400 @@
+package dockerfile
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	cf "github.com/konveyor/asset-generation/pkg/providers/discoverers/cloud_foundry"
+	"github.com/konveyor/asset-generation/pkg/providers/generators"
+)
+
+// Format is the kind of build file generated for the application.
+type Format string
+
+const (
+	// DockerfileFormat generates a Dockerfile based on the recommended base image of the application.
+	DockerfileFormat Format = "Dockerfile"
+	// CNBFormat generates a Cloud Native Buildpacks `project.toml` descriptor.
+	CNBFormat Format = "CNB"
+)
+
+const (
+	dockerfileName   = "Dockerfile"
+	projectTOMLName  = "project.toml"
+	projectTemplate  = "project.toml.tmpl"
+	commonTemplate   = "common.tmpl"
+	defaultTemplate  = "default"
+	dockerfileSuffix = ".Dockerfile.tmpl"
+	// applicationPort is the port Cloud Foundry assigns to the applications in the $PORT environment variable.
+	applicationPort = 8080
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// sensitiveEnv matches the environment variable names whose values are not written in the generated files.
+var sensitiveEnv = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|SECRET|TOKEN|CREDENTIAL|PRIVATE_KEY|API_KEY|ACCESS_KEY)`)
+
+// concealedValue matches the references to the sensitive values extracted during discovery, e.g. `$(<uuid>)`.
+var concealedValue = regexp.MustCompile(`^\$\([0-9a-fA-F-]+\)$`)
+
+type Config struct {
+	// Application is the discovered application to build.
+	Application cf.Application
+	// Format selects the generated file. Defaults to DockerfileFormat.
+	Format Format
+	// TemplatesDir is an optional directory with templates that replace the embedded ones: `<entry>.Dockerfile.tmpl`
+	// for the image catalog entries (e.g. `java.Dockerfile.tmpl`), `default.Dockerfile.tmpl`, `project.toml.tmpl`
+	// and `common.tmpl` for the shared blocks.
+	TemplatesDir string
+	// Catalog resolves the images of the application when it was discovered without image resolution. Defaults to
+	// the default image catalog.
+	Catalog *cf.ImageCatalog
+}
+
+type dockerfileProvider struct {
+	cfg Config
+}
+
+func New(cfg Config) generators.Provider {
+	return &dockerfileProvider{cfg: cfg}
+}
+
+// envVar is an environment variable set in the generated file.
+type envVar struct {
+	Name  string
+	Value string
+}
+
+// process is a process of the application with its command.
+type process struct {
+	Type    string
+	Command string
+}
+
+// templateData contains the values available to the templates.
+type templateData struct {
+	// Name is the name of the application.
+	Name string
+	// BaseImage, BuildImage, Builder and Buildpacks are the recommended images and Cloud Native Buildpacks.
+	BaseImage  string
+	BuildImage string
+	Builder    string
+	Buildpacks []string
+	// Source is the path, relative to the build context, of the application bits to copy into the image.
+	Source string
+	// Artifact is the file name of the application bits when they are a single file, e.g. `app.jar`.
+	Artifact string
+	// Env contains the environment variables that do not contain sensitive information, sorted by name.
+	Env []envVar
+	// OmittedEnv contains the names of the environment variables that may contain sensitive information.
+	OmittedEnv []string
+	// Ports contains the ports to expose: the ports of the destinations of the routes and the ports of the TCP routes,
+	// sorted.
+	Ports []int
+	// TCPRoutes contains the TCP routes of the application.
+	TCPRoutes []string
+	// Command is the command of the web process, or of the first process when there is no web process.
+	Command string
+	// Processes contains the processes that define a command.
+	Processes []process
+	// Application is the discovered application.
+	Application cf.Application
+}
+
+// Generate returns the Dockerfile, or the `project.toml` for the CNB format, generated for the application, keyed
+// by its file name.
+func (p *dockerfileProvider) Generate() (map[string]string, error) {
+	app := p.cfg.Application
+	if app.Docker.Image != "" {
+		return nil, fmt.Errorf("application %s already runs the container image %s", app.Name, app.Docker.Image)
+	}
+	data, err := p.templateData()
+	if err != nil {
+		return nil, err
+	}
+	name, fileName := p.templateName(data), dockerfileName
+	if p.cfg.Format == CNBFormat {
+		name, fileName = projectTemplate, projectTOMLName
+	} else if data.BaseImage == "" {
+		return nil, fmt.Errorf("no base image found for application %s: add its buildpacks to the image catalog", app.Name)
+	}
+	t, err := p.loadTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("failed to render the template %s: %w", t.Name(), err)
+	}
+	return map[string]string{fileName: strings.Trim(b.String(), "\n") + "\n"}, nil
+}
+
+// templateName returns the name of the Dockerfile template matching the image catalog entry of the application.
+func (p *dockerfileProvider) templateName(data templateData) string {
+	entry := defaultTemplate
+	if data.Application.ContainerImage != nil && data.Application.ContainerImage.Entry != "" {
+		entry = data.Application.ContainerImage.Entry
+	}
+	name := entry + dockerfileSuffix
+	if p.templateExists(name) {
+		return name
+	}
+	return defaultTemplate + dockerfileSuffix
+}
+
+func (p *dockerfileProvider) templateExists(name string) bool {
+	if p.cfg.TemplatesDir != "" {
+		if _, err := os.Stat(filepath.Join(p.cfg.TemplatesDir, name)); err == nil {
+			return true
+		}
+	}
+	_, err := fs.Stat(templates, path.Join("templates", name))
+	return err == nil
+}
+
+// loadTemplate parses the common template and the named template, preferring the ones in the templates directory.
+func (p *dockerfileProvider) loadTemplate(name string) (*template.Template, error) {
+	t := template.New(name).Funcs(template.FuncMap{
+		"join":     strings.Join,
+		"quote":    tomlQuote,
+		"envQuote": envQuote,
+		"exec":     execForm,
+		"required": required,
+		"comment":  comment,
+	})
+	for _, n := range []string{commonTemplate, name} {
+		content, err := p.readTemplate(n)
+		if err != nil {
+			return nil, err
+		}
+		tt := t
+		if n != name {
+			tt = t.New(n)
+		}
+		if _, err := tt.Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("failed to parse the template %s: %w", n, err)
+		}
+	}
+	return t, nil
+}
+
+func (p *dockerfileProvider) readTemplate(name string) ([]byte, error) {
+	if p.cfg.TemplatesDir != "" {
+		content, err := os.ReadFile(filepath.Join(p.cfg.TemplatesDir, name))
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read the template %s: %w", name, err)
+		}
+	}
+	return templates.ReadFile(path.Join("templates", name))
+}
+
+func (p *dockerfileProvider) templateData() (templateData, error) {
+	app := p.cfg.Application
+	if app.ContainerImage == nil {
+		catalog := p.cfg.Catalog
+		if catalog == nil {
+			var err error
+			if catalog, err = cf.DefaultImageCatalog(); err != nil {
+				return templateData{}, err
+			}
+		}
+		app.ContainerImage = catalog.Resolve(app)
+	}
+	data := templateData{Name: app.Name, Application: app}
+	if img := app.ContainerImage; img != nil {
+		data.BaseImage, data.BuildImage, data.Builder, data.Buildpacks = img.BaseImage, img.BuildImage, img.Builder, img.Buildpacks
+	}
+	data.Source, data.Artifact = applicationSource(app.Path)
+
+	for _, proc := range app.Processes {
+		if proc.Command == "" {
+			continue
+		}
+		data.Processes = append(data.Processes, process{Type: string(proc.Type), Command: proc.Command})
+		if data.Command == "" || proc.Type == cf.Web {
+			data.Command = proc.Command
+		}
+	}
+
+	env := app.Env
+	if hasRoutes(app) {
+		data.Ports = routePorts(app)
+		if _, ok := env["PORT"]; !ok {
+			data.Env = append(data.Env, envVar{Name: "PORT", Value: strconv.Itoa(applicationPort)})
+		}
+	}
+	for name, value := range env {
+		if sensitiveEnv.MatchString(name) || concealedValue.MatchString(value) {
+			data.OmittedEnv = append(data.OmittedEnv, name)
+			continue
+		}
+		data.Env = append(data.Env, envVar{Name: name, Value: value})
+	}
+	sort.Slice(data.Env, func(i, j int) bool { return data.Env[i].Name < data.Env[j].Name })
+	slices.Sort(data.OmittedEnv)
+
+	for _, r := range app.Routes.Routes {
+		if r.Protocol == cf.TCPRouteProtocol {
+			data.TCPRoutes = append(data.TCPRoutes, r.Route)
+		}
+	}
+	return data, nil
+}
+
+// applicationSource returns the path of the application bits relative to the build context, which is the directory
+// of the manifest, and the file name of the bits when they are a single artifact.
+func applicationSource(appPath string) (string, string) {
+	if appPath == "" {
+		return ".", ""
+	}
+	source := filepath.ToSlash(appPath)
+	if _, entry, ok := strings.Cut(source, "!/"); ok {
+		source = path.Base(entry)
+	}
+	if path.IsAbs(source) || !filepath.IsLocal(filepath.FromSlash(source)) {
+		source = path.Base(source)
+	}
+	switch strings.ToLower(path.Ext(source)) {
+	case ".jar", ".war", ".zip":
+		return source, path.Base(source)
+	}
+	return source, ""
+}
+
+// routePorts returns the sorted ports the application receives traffic on: the ports of the destinations of its routes,
+// the port assigned by Cloud Foundry for the routes without destinations, and the ports of its TCP routes.
+func routePorts(app cf.Application) []int {
+	ports := []int{}
+	add := func(port int) {
+		if port > 0 && !slices.Contains(ports, port) {
+			ports = append(ports, port)
+		}
+	}
+	if app.Routes.RandomRoute {
+		add(applicationPort)
+	}
+	for _, r := range app.Routes.Routes {
+		destinations := 0
+		for _, d := range r.Destinations {
+			if d.App != "" && d.App != app.Name {
+				continue
+			}
+			destinations++
+			if d.Port == 0 {
+				add(applicationPort)
+			} else {
+				add(d.Port)
+			}
+		}
+		if destinations == 0 {
+			add(applicationPort)
+		}
+		if r.Protocol == cf.TCPRouteProtocol {
+			if i := strings.LastIndex(r.Route, ":"); i >= 0 {
+				port, err := strconv.Atoi(r.Route[i+1:])
+				if err == nil {
+					add(port)
+				}
+			}
+		}
+	}
+	slices.Sort(ports)
+	return ports
+}
+
+// hasRoutes returns true when the application receives traffic on the port assigned by Cloud Foundry.
+func hasRoutes(app cf.Application) bool {
+	return !app.Routes.NoRoute && (app.Routes.RandomRoute || len(app.Routes.Routes) > 0)
+}
+
+// envQuote quotes the value of an ENV instruction, escaping the characters interpreted by the Dockerfile parser.
+func envQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`).Replace(s) + `"`
+}
+
+// required returns the value, or fails the rendering of the template when the value is empty, e.g. the build image of
+// a multi-stage build missing from the image catalog entry.
+func required(name, value string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("no %s found: add it to the image catalog entry", name)
+	}
+	return value, nil
+}
+
+// tomlQuote quotes the value as a TOML basic string, escaping the quotes, the backslashes and the control characters,
+// which TOML only allows in their `\uXXXX` form.
+func tomlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// comment returns the text to write in a comment, continuing the comment on each of its lines so that a multi-line
+// value, e.g. a command defined as a YAML block scalar, is not written as instructions. The other control characters,
+// which TOML does not allow in comments, are replaced by spaces.
+func comment(s string) string {
+	lines := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(strings.TrimRight(s, "\r\n")), "\n")
+	for i, l := range lines {
+		lines[i] = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) && r != '\t' {
+				return ' '
+			}
+			return r
+		}, l)
+	}
+	return strings.Join(lines, "\n#   ")
+}
+
+// execForm returns the command in the JSON exec form of the CMD instruction.
+func execForm(s string) (string, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode([]string{s}); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
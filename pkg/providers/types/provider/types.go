@@ -7,4 +7,19 @@ type DiscoverResult struct {
 	Content map[string]any
 	// Secret contains sensitive information such as credentials and tokens
 	Secret map[string]any
+	// Warnings contains the problems that did not prevent the discovery from completing: the invalid values
+	// that were adjusted or dropped in lenient mode, the optional resources that could not be retrieved and the
+	// disagreements between the sources of the discovered data
+	Warnings []Warning
+}
+
+// Warning describes a part of the discovered data that could not be discovered as is, such as a value that
+// was not valid and was adjusted or dropped, or a resource that could not be retrieved.
+type Warning struct {
+	// Path identifies the field or the resource concerned
+	Path string `json:"path"`
+	// Value contains the original value of the field, when there is one
+	Value any `json:"value,omitempty"`
+	// Message describes the problem and the adjustment made, if any
+	Message string `json:"message"`
 }